@@ -0,0 +1,86 @@
+package cache
+
+// Backend stores cache entries.
+//
+// Cache serializes writes (Store, Delete, Clear) under its write lock, but
+// Load, Len and Range may be called concurrently with each other.
+// TTL handling, bounds and eviction are done by Cache; a backend only stores
+// what it is given.
+type Backend interface {
+	// Load returns the entry stored under key.
+	Load(key string) (*Entry, bool)
+	// Store saves e under key, replacing any previous entry.
+	Store(key string, e *Entry)
+	// Delete removes key.
+	Delete(key string)
+	// Len returns the number of stored entries, including expired ones.
+	Len() int
+	// Range calls fn for each entry until fn returns false.
+	// fn must not call back into the backend.
+	Range(fn func(key string, e *Entry) bool)
+	// Clear removes all entries.
+	Clear()
+}
+
+// memoryBackend is the default Backend, a plain map.
+// Concurrent reads of a map are safe, and Cache serializes writes.
+type memoryBackend struct {
+	entries map[string]*Entry
+}
+
+// NewMemoryBackend creates the default in-memory backend.
+func NewMemoryBackend() Backend {
+	return &memoryBackend{entries: make(map[string]*Entry)}
+}
+
+func (b *memoryBackend) Load(key string) (*Entry, bool) {
+	e, ok := b.entries[key]
+
+	return e, ok
+}
+
+func (b *memoryBackend) Store(key string, e *Entry) {
+	b.entries[key] = e
+}
+
+func (b *memoryBackend) Delete(key string) {
+	delete(b.entries, key)
+}
+
+func (b *memoryBackend) Len() int {
+	return len(b.entries)
+}
+
+func (b *memoryBackend) Range(fn func(key string, e *Entry) bool) {
+	for key, e := range b.entries {
+		if !fn(key, e) {
+			return
+		}
+	}
+}
+
+func (b *memoryBackend) Clear() {
+	b.entries = make(map[string]*Entry)
+}
+
+// SharedBackend is a Backend whose entries may also change behind the
+// Cache's back, for example because other processes write to the same file.
+//
+// Cache calls Sync under its write lock before relying on its own
+// bookkeeping (entry count, byte size, key and tag indexes, eviction order)
+// and applies the returned changes.
+type SharedBackend interface {
+	Backend
+	// Sync picks up changes made elsewhere and returns every change to the
+	// stored entries since the last call that was not made through Store,
+	// Delete or Clear.
+	Sync() []BackendChange
+}
+
+// BackendChange describes an entry a SharedBackend added, replaced or removed
+// on its own. Old is nil for an added entry and New is nil for a removed one.
+type BackendChange struct {
+	Key string
+	Old *Entry
+	New *Entry
+}
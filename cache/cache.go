@@ -22,11 +22,20 @@
 //	    // val is the cached data - do not modify it directly
 //	    data := val.(*MyType) // type assertion for retrieval
 //	}
+//
+// Bounded caches:
+//
+//	c := cache.New(cache.WithMaxEntries(1000), cache.WithEvictionPolicy(cache.NewLFU()))
+//
+// When a bound is configured, Set evicts entries chosen by the eviction policy
+// (LRU by default) until the new entry fits.
 package cache
 
 import (
 	"sync"
 	"time"
+
+	"github.com/valksor/go-toolkit/eventbus"
 )
 
 // Default TTL values for different resource types.
@@ -38,32 +47,197 @@ const (
 	DefaultPluginTTL   = 10 * time.Minute
 )
 
-// entry represents a cached item with expiration.
-type entry struct {
-	data      any
-	expiresAt time.Time
+// Entry represents a cached item with expiration.
+//
+// Backends store entries; only Value and ExpiresAt are meant to be persisted.
+// Entries are never mutated once stored.
+type Entry struct {
+	Value     any
+	ExpiresAt time.Time
+
+	size int64
+	// err is set for negative entries cached by GetOrLoad; Get treats them as misses.
+	err error
+	// refresher and refreshAt are set for stale-while-revalidate entries.
+	// Reads at or after refreshAt trigger a background refresh.
+	refresher *refresher
+	refreshAt time.Time
+	// tags are set by SetWithTags and indexed for InvalidateTag.
+	tags []string
 }
 
 // isExpired returns true if the entry has expired.
-func (e *entry) isExpired() bool {
-	return time.Now().After(e.expiresAt)
+func (e *Entry) isExpired() bool {
+	return time.Now().After(e.ExpiresAt)
 }
 
 // Cache is a thread-safe in-memory cache with TTL support.
 type Cache struct {
-	mu    sync.RWMutex
-	store map[string]*entry
+	mu      sync.RWMutex
+	backend Backend
+	// shared is backend if it is a SharedBackend, nil otherwise.
+	shared SharedBackend
 
 	// Enabled allows the cache to be disabled at runtime
 	enabled bool
+
+	// Bounds (zero means unbounded) and the policy used to enforce them.
+	// policy is nil for unbounded caches.
+	maxEntries int
+	maxBytes   int64
+	sizeOf     SizeFunc
+	policy     EvictionPolicy
+	bytes      int64
+
+	// Indexes for DeletePrefix and InvalidateTag.
+	keys *keyIndex
+	tags map[string]map[string]struct{}
+
+	stats counters
+	bus   *eventbus.Bus
+
+	// In-flight GetOrLoad calls, used to coalesce concurrent loads per key.
+	// When both are needed, loadMu is taken before mu.
+	loadMu      sync.Mutex
+	calls       map[string]*call
+	negativeTTL time.Duration
+	isNegative  func(error) bool
+
+	// Stale-while-revalidate settings.
+	refreshAhead      float64
+	refreshTimeout    time.Duration
+	refreshRetryDelay time.Duration
+	onRefreshError    func(key string, err error)
+}
+
+// Option configures a Cache instance.
+type Option func(*options)
+
+type options struct {
+	maxEntries int
+	maxBytes   int64
+	sizeOf     SizeFunc
+	policy     EvictionPolicy
+	backend    Backend
+	bus        *eventbus.Bus
+
+	negativeTTL time.Duration
+	isNegative  func(error) bool
+
+	refreshAhead      float64
+	refreshTimeout    time.Duration
+	refreshRetryDelay time.Duration
+	onRefreshError    func(key string, err error)
+}
+
+// SizeFunc estimates the size in bytes of a cached value.
+type SizeFunc func(key string, data any) int64
+
+// WithMaxEntries bounds the cache to at most n entries.
+// Values <= 0 leave the entry count unbounded.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxEntries = n
+		}
+	}
+}
+
+// WithMaxBytes bounds the total estimated size of cached values to maxBytes.
+// The size of each value is computed by sizeOf when it is stored.
+// Values <= 0 or a nil sizeOf leave the byte budget unbounded.
+func WithMaxBytes(maxBytes int64, sizeOf SizeFunc) Option {
+	return func(o *options) {
+		if maxBytes > 0 && sizeOf != nil {
+			o.maxBytes = maxBytes
+			o.sizeOf = sizeOf
+		}
+	}
+}
+
+// WithEvictionPolicy sets the policy used to choose entries to evict when a
+// bound is reached. Defaults to LRU. The policy is ignored for unbounded caches.
+// A policy instance must not be shared between caches.
+func WithEvictionPolicy(policy EvictionPolicy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// WithBackend sets the storage backend. Defaults to an in-memory map.
+// A backend instance must not be shared between caches.
+func WithBackend(backend Backend) Option {
+	return func(o *options) {
+		o.backend = backend
+	}
 }
 
 // New creates a new Cache instance.
-func New() *Cache {
-	return &Cache{
-		store:   make(map[string]*entry),
-		enabled: true,
+func New(opts ...Option) *Cache {
+	o := &options{
+		refreshTimeout:    DefaultRefreshTimeout,
+		refreshRetryDelay: DefaultRefreshRetryDelay,
 	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	backend := o.backend
+	if backend == nil {
+		backend = NewMemoryBackend()
+	}
+
+	c := &Cache{
+		backend:     backend,
+		keys:        newKeyIndex(),
+		tags:        make(map[string]map[string]struct{}),
+		bus:         o.bus,
+		enabled:     true,
+		maxEntries:  o.maxEntries,
+		maxBytes:    o.maxBytes,
+		sizeOf:      o.sizeOf,
+		calls:       make(map[string]*call),
+		negativeTTL: o.negativeTTL,
+		isNegative:  o.isNegative,
+
+		refreshAhead:      o.refreshAhead,
+		refreshTimeout:    o.refreshTimeout,
+		refreshRetryDelay: o.refreshRetryDelay,
+		onRefreshError:    o.onRefreshError,
+	}
+
+	if c.bounded() {
+		c.policy = o.policy
+		if c.policy == nil {
+			c.policy = NewLRU()
+		}
+	}
+
+	// Account for entries a persistent backend already holds.
+	c.shared, _ = c.backend.(SharedBackend)
+	if c.shared != nil {
+		c.shared.Sync()
+	}
+	c.backend.Range(func(key string, e *Entry) bool {
+		c.indexLocked(key, e)
+		if c.sizeOf != nil && e.err == nil {
+			e.size = c.sizeOf(key, e.Value)
+			c.bytes += e.size
+		}
+		if c.policy != nil {
+			c.policy.Add(key)
+		}
+
+		return true
+	})
+
+	return c
+}
+
+// bounded returns true if the cache has an entry or byte limit.
+func (c *Cache) bounded() bool {
+	return c.maxEntries > 0 || c.maxBytes > 0
 }
 
 // Enable enables the cache.
@@ -95,15 +269,28 @@ func (c *Cache) Enabled() bool {
 // the returned value directly, as it will corrupt the cache. Make a copy if
 // modification is needed. The caller should type-assert the result to the expected type.
 func (c *Cache) Get(key string) (any, bool) {
+	e, ok := c.lookup(key)
+	if !ok || e.err != nil {
+		return nil, false
+	}
+
+	return e.Value, true
+}
+
+// lookup returns the live entry for key, including negative entries.
+// Entries are never mutated once stored, so the result may be read without the lock.
+func (c *Cache) lookup(key string) (*Entry, bool) {
 	if !c.Enabled() {
 		return nil, false
 	}
 
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	e, ok := c.store[key]
+	e, ok := c.backend.Load(key)
 	if !ok {
+		c.mu.RUnlock()
+		c.stats.misses.Add(1)
+		c.emit(EventMiss, key)
+
 		return nil, false
 	}
 
@@ -111,64 +298,352 @@ func (c *Cache) Get(key string) (any, bool) {
 	// The background cleanup scheduler will handle deletion.
 	// This avoids lock promotion (read -> write) which causes contention.
 	if e.isExpired() {
+		c.mu.RUnlock()
+		c.stats.misses.Add(1)
+		c.stats.expiredReads.Add(1)
+		c.emit(EventExpired, key)
+
 		return nil, false
 	}
 
-	return e.data, true
+	if c.policy != nil {
+		c.policy.Access(key)
+	}
+	c.mu.RUnlock()
+
+	c.stats.hits.Add(1)
+	c.emit(EventHit, key)
+
+	// Started outside the read lock: refreshing takes loadMu, and load
+	// holds loadMu while storing results.
+	if e.needsRefresh() {
+		c.refresh(key, e.refresher)
+	}
+
+	return e, true
 }
 
 // Set stores a value in the cache with the given TTL.
 func (c *Cache) Set(key string, data any, ttl time.Duration) {
+	c.set(key, &Entry{Value: data, ExpiresAt: time.Now().Add(ttl)})
+}
+
+// set stores e under key, enforcing bounds, and publishes the events.
+func (c *Cache) set(key string, e *Entry) {
+	if evicted, ok := c.store(key, e); ok {
+		c.emitSet(key, evicted)
+	}
+}
+
+// store stores e under key, enforcing bounds. The entry size is computed here.
+// It returns the keys evicted to make room and whether e was stored, leaving
+// the events to the caller so they can be published once no lock is held.
+func (c *Cache) store(key string, e *Entry) ([]string, bool) {
 	if !c.Enabled() {
+		return nil, false
+	}
+
+	var size int64
+	if c.sizeOf != nil && e.err == nil {
+		size = c.sizeOf(key, e.Value)
+	}
+
+	c.mu.Lock()
+	c.syncLocked()
+
+	// A value larger than the whole budget can never fit.
+	// Drop any previous value so Get does not return stale data.
+	if c.maxBytes > 0 && size > c.maxBytes {
+		c.removeLocked(key)
+		c.mu.Unlock()
+
+		return nil, false
+	}
+
+	evicted := c.makeRoomLocked(key, size)
+	c.syncLocked()
+
+	if old, ok := c.backend.Load(key); ok {
+		c.bytes -= old.size
+		c.unindexLocked(key, old)
+		if c.policy != nil {
+			c.policy.Access(key)
+		}
+	} else if c.policy != nil {
+		c.policy.Add(key)
+	}
+
+	e.size = size
+	c.backend.Store(key, e)
+	c.bytes += size
+	c.indexLocked(key, e)
+
+	// Storing may have picked up entries written elsewhere.
+	c.syncLocked()
+	evicted = append(evicted, c.trimLocked()...)
+	c.mu.Unlock()
+
+	c.stats.sets.Add(1)
+
+	return evicted, true
+}
+
+// emitSet publishes the events for storing key and evicting the given keys.
+func (c *Cache) emitSet(key string, evicted []string) {
+	c.emit(EventSet, key)
+	for _, victim := range evicted {
+		c.emit(EventEvict, victim)
+	}
+}
+
+// makeRoomLocked evicts entries until an entry of the given size can be stored
+// under key without exceeding the configured bounds, returning the evicted keys.
+// Must be called with c.mu held.
+func (c *Cache) makeRoomLocked(key string, size int64) []string {
+	if c.policy == nil {
+		return nil
+	}
+
+	var evicted []string
+
+	for {
+		count := c.backend.Len()
+		bytes := c.bytes + size
+		if old, ok := c.backend.Load(key); ok {
+			bytes -= old.size
+		} else {
+			count++
+		}
+
+		overCount := c.maxEntries > 0 && count > c.maxEntries
+		overBytes := c.maxBytes > 0 && bytes > c.maxBytes
+		if !overCount && !overBytes {
+			return evicted
+		}
+
+		victim, ok := c.policy.Victim()
+		if !ok {
+			return evicted
+		}
+
+		if c.removeLocked(victim) {
+			c.stats.evictions.Add(1)
+			evicted = append(evicted, victim)
+		}
+	}
+}
+
+// trimLocked evicts entries until the cache is within its bounds, returning
+// the evicted keys. Only a shared backend can exceed them, by picking up
+// entries written elsewhere. Must be called with c.mu held.
+func (c *Cache) trimLocked() []string {
+	if c.policy == nil {
+		return nil
+	}
+
+	var evicted []string
+
+	for {
+		overCount := c.maxEntries > 0 && c.backend.Len() > c.maxEntries
+		overBytes := c.maxBytes > 0 && c.bytes > c.maxBytes
+		if !overCount && !overBytes {
+			return evicted
+		}
+
+		victim, ok := c.policy.Victim()
+		if !ok {
+			return evicted
+		}
+
+		if c.removeLocked(victim) {
+			c.stats.evictions.Add(1)
+			evicted = append(evicted, victim)
+		}
+	}
+}
+
+// syncLocked applies the changes a shared backend made on its own to the
+// accounting, indexes and eviction policy. Must be called with c.mu held for
+// writing.
+func (c *Cache) syncLocked() {
+	if c.shared == nil {
+		return
+	}
+
+	for _, ch := range c.shared.Sync() {
+		if ch.Old != nil {
+			c.bytes -= ch.Old.size
+			c.unindexLocked(ch.Key, ch.Old)
+		}
+
+		if ch.New == nil {
+			if c.policy != nil {
+				c.policy.Remove(ch.Key)
+			}
+
+			continue
+		}
+
+		if c.sizeOf != nil && ch.New.err == nil {
+			ch.New.size = c.sizeOf(ch.Key, ch.New.Value)
+			c.bytes += ch.New.size
+		}
+		c.indexLocked(ch.Key, ch.New)
+		if c.policy != nil {
+			c.policy.Add(ch.Key)
+		}
+	}
+}
+
+// syncShared brings the bookkeeping up to date with a shared backend and
+// evicts entries it picked up beyond the bounds.
+func (c *Cache) syncShared() {
+	if c.shared == nil {
 		return
 	}
 
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.syncLocked()
+	evicted := c.trimLocked()
+	c.mu.Unlock()
 
-	c.store[key] = &entry{
-		data:      data,
-		expiresAt: time.Now().Add(ttl),
+	for _, victim := range evicted {
+		c.emit(EventEvict, victim)
 	}
 }
 
+// removeLocked deletes key and updates accounting, returning true if an entry
+// was removed. Must be called with c.mu held for writing.
+func (c *Cache) removeLocked(key string) bool {
+	// Deleting from a shared backend merges changes made elsewhere.
+	c.syncLocked()
+
+	// Always forget the key in the policy: a shared persistent backend
+	// may have dropped the entry already.
+	if c.policy != nil {
+		c.policy.Remove(key)
+	}
+
+	e, ok := c.backend.Load(key)
+	if !ok {
+		c.keys.remove(key)
+
+		return false
+	}
+
+	c.backend.Delete(key)
+	c.bytes -= e.size
+	c.unindexLocked(key, e)
+
+	return true
+}
+
 // Delete removes a value from the cache.
+// A load or refresh of key in flight is not stored when it completes.
 func (c *Cache) Delete(key string) {
+	c.loadMu.Lock()
+	delete(c.calls, key)
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	delete(c.store, key)
+	c.syncLocked()
+	removed := c.removeLocked(key)
+	c.mu.Unlock()
+	c.loadMu.Unlock()
+
+	if removed {
+		c.stats.deletes.Add(1)
+		c.emit(EventDelete, key)
+	}
 }
 
 // Clear removes all entries from the cache.
+// Loads and refreshes in flight are not stored when they complete.
 func (c *Cache) Clear() {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	clear(c.calls)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.store = make(map[string]*entry)
+
+	c.syncLocked()
+
+	if c.policy != nil {
+		c.backend.Range(func(key string, _ *Entry) bool {
+			c.policy.Remove(key)
+
+			return true
+		})
+	}
+
+	c.backend.Clear()
+	c.bytes = 0
+	c.keys = newKeyIndex()
+	c.tags = make(map[string]map[string]struct{})
 }
 
 // Size returns the number of entries in the cache.
 func (c *Cache) Size() int {
+	c.syncShared()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.backend.Len()
+}
+
+// Bytes returns the estimated total size of cached values.
+// Always zero unless WithMaxBytes is configured.
+func (c *Cache) Bytes() int64 {
+	c.syncShared()
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return len(c.store)
+	return c.bytes
+}
+
+// Evictions returns the number of entries evicted to stay within bounds.
+// Expired entries removed by Cleanup are not counted.
+func (c *Cache) Evictions() uint64 {
+	return c.stats.evictions.Load()
 }
 
 // Cleanup removes all expired entries from the cache.
 func (c *Cache) Cleanup() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.syncLocked()
 
-	for key, e := range c.store {
+	var expired []string
+	c.backend.Range(func(key string, e *Entry) bool {
 		if e.isExpired() {
-			delete(c.store, key)
+			expired = append(expired, key)
+		}
+
+		return true
+	})
+
+	removed := expired[:0]
+	for _, key := range expired {
+		if c.removeLocked(key) {
+			removed = append(removed, key)
 		}
 	}
+	c.mu.Unlock()
+
+	c.stats.cleanups.Add(1)
+	c.stats.cleanedUp.Add(uint64(len(removed)))
+	for _, key := range removed {
+		c.emit(EventCleanup, key)
+	}
 }
 
 // StartCleanupScheduler runs periodic cleanup of expired entries
 // in a separate goroutine. The returned channel can be used to stop
 // the scheduler by closing it.
+//
+// Closing the channel twice panics; prefer StartCleanup, which stops on
+// context cancellation and can be stopped and awaited safely.
 func (c *Cache) StartCleanupScheduler(interval time.Duration) chan struct{} {
 	stop := make(chan struct{})
 
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// EvictionPolicy chooses which entry to evict when a bounded cache is full.
+//
+// Cache calls Access while holding only a read lock, so implementations
+// must be safe for concurrent use.
+type EvictionPolicy interface {
+	// Add records that key was inserted.
+	Add(key string)
+	// Access records that key was read or overwritten.
+	Access(key string)
+	// Remove forgets key.
+	Remove(key string)
+	// Victim returns the key that should be evicted next, or false if empty.
+	Victim() (string, bool)
+}
+
+// lru evicts the least recently used entry.
+type lru struct {
+	mu    sync.Mutex
+	order *list.List // front = most recently used
+	items map[string]*list.Element
+}
+
+// NewLRU creates a least-recently-used eviction policy.
+func NewLRU() EvictionPolicy {
+	return &lru{
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (p *lru) Add(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if el, ok := p.items[key]; ok {
+		p.order.MoveToFront(el)
+
+		return
+	}
+
+	p.items[key] = p.order.PushFront(key)
+}
+
+func (p *lru) Access(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if el, ok := p.items[key]; ok {
+		p.order.MoveToFront(el)
+	}
+}
+
+func (p *lru) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if el, ok := p.items[key]; ok {
+		p.order.Remove(el)
+		delete(p.items, key)
+	}
+}
+
+func (p *lru) Victim() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	el := p.order.Back()
+	if el == nil {
+		return "", false
+	}
+
+	key, _ := el.Value.(string)
+
+	return key, true
+}
+
+// lfuBucket groups keys that share an access frequency.
+type lfuBucket struct {
+	freq  uint64
+	items *list.List // front = most recently used
+}
+
+// lfuItem locates a key within the frequency buckets.
+type lfuItem struct {
+	bucket *list.Element // element of lfu.buckets
+	elem   *list.Element // element of bucket.items
+}
+
+// lfu evicts the least frequently used entry, breaking ties by recency.
+// All operations are O(1).
+type lfu struct {
+	mu      sync.Mutex
+	buckets *list.List // of *lfuBucket, ascending frequency
+	items   map[string]*lfuItem
+}
+
+// NewLFU creates a least-frequently-used eviction policy.
+// Entries with equal frequency are evicted least recently used first.
+func NewLFU() EvictionPolicy {
+	return &lfu{
+		buckets: list.New(),
+		items:   make(map[string]*lfuItem),
+	}
+}
+
+func (p *lfu) Add(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.items[key]; ok {
+		p.incrementLocked(key)
+
+		return
+	}
+
+	front := p.buckets.Front()
+	if front == nil || bucketOf(front).freq != 1 {
+		front = p.buckets.PushFront(&lfuBucket{freq: 1, items: list.New()})
+	}
+
+	p.items[key] = &lfuItem{
+		bucket: front,
+		elem:   bucketOf(front).items.PushFront(key),
+	}
+}
+
+func (p *lfu) Access(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.items[key]; ok {
+		p.incrementLocked(key)
+	}
+}
+
+// incrementLocked moves key to the next frequency bucket.
+func (p *lfu) incrementLocked(key string) {
+	item := p.items[key]
+	cur := bucketOf(item.bucket)
+
+	next := item.bucket.Next()
+	if next == nil || bucketOf(next).freq != cur.freq+1 {
+		next = p.buckets.InsertAfter(&lfuBucket{freq: cur.freq + 1, items: list.New()}, item.bucket)
+	}
+
+	cur.items.Remove(item.elem)
+	if cur.items.Len() == 0 {
+		p.buckets.Remove(item.bucket)
+	}
+
+	item.bucket = next
+	item.elem = bucketOf(next).items.PushFront(key)
+}
+
+func (p *lfu) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	item, ok := p.items[key]
+	if !ok {
+		return
+	}
+
+	b := bucketOf(item.bucket)
+	b.items.Remove(item.elem)
+	if b.items.Len() == 0 {
+		p.buckets.Remove(item.bucket)
+	}
+
+	delete(p.items, key)
+}
+
+func (p *lfu) Victim() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	front := p.buckets.Front()
+	if front == nil {
+		return "", false
+	}
+
+	key, _ := bucketOf(front).items.Back().Value.(string)
+
+	return key, true
+}
+
+func bucketOf(el *list.Element) *lfuBucket {
+	b, _ := el.Value.(*lfuBucket)
+
+	return b
+}
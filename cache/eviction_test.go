@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_MaxEntriesLRU(t *testing.T) {
+	c := New(WithMaxEntries(2))
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+
+	// Touch "a" so "b" becomes least recently used
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected to find a")
+	}
+
+	c.Set("c", 3, time.Minute)
+
+	if c.Size() != 2 {
+		t.Fatalf("expected size 2, got %d", c.Size())
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to survive eviction")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("expected c to be stored")
+	}
+	if c.Evictions() != 1 {
+		t.Fatalf("expected 1 eviction, got %d", c.Evictions())
+	}
+}
+
+func TestCache_MaxEntriesLFU(t *testing.T) {
+	c := New(WithMaxEntries(2), WithEvictionPolicy(NewLFU()))
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+
+	// "a" is read more often than "b"
+	for range 3 {
+		c.Get("a")
+	}
+	c.Get("b")
+
+	c.Set("c", 3, time.Minute)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to survive eviction")
+	}
+
+	// The newly inserted entry must not be evicted by its own Set
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("expected c to be stored")
+	}
+}
+
+func TestCache_OverwriteDoesNotEvict(t *testing.T) {
+	c := New(WithMaxEntries(2))
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	c.Set("a", 10, time.Minute)
+
+	if c.Size() != 2 {
+		t.Fatalf("expected size 2, got %d", c.Size())
+	}
+	if c.Evictions() != 0 {
+		t.Fatalf("expected no evictions, got %d", c.Evictions())
+	}
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	sizeOf := func(_ string, data any) int64 {
+		s, _ := data.(string)
+
+		return int64(len(s))
+	}
+	c := New(WithMaxBytes(10, sizeOf))
+
+	c.Set("a", "aaaa", time.Minute)
+	c.Set("b", "bbbb", time.Minute)
+
+	if c.Bytes() != 8 {
+		t.Fatalf("expected 8 bytes, got %d", c.Bytes())
+	}
+
+	c.Set("c", "cccc", time.Minute)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected a to be evicted")
+	}
+	if c.Bytes() != 8 {
+		t.Fatalf("expected 8 bytes after eviction, got %d", c.Bytes())
+	}
+
+	// A value larger than the budget is not stored and drops the old value
+	c.Set("b", "this value is too large", time.Minute)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected oversized value to be rejected")
+	}
+	if c.Bytes() != 4 {
+		t.Fatalf("expected 4 bytes, got %d", c.Bytes())
+	}
+
+	c.Clear()
+	if c.Bytes() != 0 {
+		t.Fatalf("expected 0 bytes after clear, got %d", c.Bytes())
+	}
+}
+
+func TestCache_BoundedCleanup(t *testing.T) {
+	c := New(WithMaxEntries(2))
+
+	c.Set("a", 1, 10*time.Millisecond)
+	c.Set("b", 2, time.Minute)
+
+	time.Sleep(20 * time.Millisecond)
+	c.Cleanup()
+
+	// Cleanup must release the policy slot, so no eviction is needed
+	c.Set("c", 3, time.Minute)
+
+	if c.Evictions() != 0 {
+		t.Fatalf("expected no evictions, got %d", c.Evictions())
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("expected b to remain")
+	}
+}
+
+func TestCache_BoundedConcurrent(t *testing.T) {
+	c := New(WithMaxEntries(50), WithEvictionPolicy(NewLFU()))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 200 {
+				key := fmt.Sprintf("key-%d-%d", i, j%80)
+				c.Set(key, j, time.Minute)
+				c.Get(key)
+			}
+		})
+	}
+	wg.Wait()
+
+	if c.Size() > 50 {
+		t.Fatalf("expected at most 50 entries, got %d", c.Size())
+	}
+}
+
+func TestLFU_TieBreaksByRecency(t *testing.T) {
+	p := NewLFU()
+
+	p.Add("a")
+	p.Add("b")
+	p.Add("c")
+
+	victim, ok := p.Victim()
+	if !ok || victim != "a" {
+		t.Fatalf("expected victim a, got %q", victim)
+	}
+
+	p.Access("a")
+	victim, _ = p.Victim()
+	if victim != "b" {
+		t.Fatalf("expected victim b, got %q", victim)
+	}
+
+	p.Remove("b")
+	p.Remove("c")
+	victim, _ = p.Victim()
+	if victim != "a" {
+		t.Fatalf("expected victim a, got %q", victim)
+	}
+
+	p.Remove("a")
+	if _, ok := p.Victim(); ok {
+		t.Fatal("expected no victim for empty policy")
+	}
+}
@@ -0,0 +1,405 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-toolkit/log"
+	"github.com/valksor/go-toolkit/paths"
+)
+
+// fileEntry is the on-disk form of an Entry.
+type fileEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// fileData is the on-disk cache file.
+type fileData struct {
+	Entries map[string]fileEntry `json:"entries"`
+}
+
+// FileBackend persists cache entries as a JSON file so they survive across
+// process invocations.
+//
+// Entries are kept in memory and written through to disk on every change.
+// Writes take an exclusive file lock and merge with the current file contents,
+// so several processes can share one cache file. Reads are served from memory;
+// a miss reloads the file if another process has changed it.
+//
+// Values are stored as JSON. Entries loaded from disk hold a json.RawMessage
+// instead of the original type: Typed decodes it transparently, while users of
+// the untyped Cache must unmarshal it themselves. Values that cannot be
+// marshaled, negative entries and refresh settings stay in memory only.
+//
+// Entries picked up from the file are reported to the Cache through Sync, so
+// bounds, byte accounting, DeletePrefix and InvalidateTag also cover entries
+// written by other processes.
+//
+// A corrupt or unreadable cache file is treated as empty and replaced on the
+// next write.
+type FileBackend struct {
+	path string
+
+	mu      sync.RWMutex
+	entries map[string]*Entry
+	// memOnly tracks keys whose entries are not persisted.
+	memOnly map[string]struct{}
+	// changes records entries added, replaced or removed by merging the
+	// file, until they are returned by Sync.
+	changes map[string]*BackendChange
+	// modTime and size identify the file version last read or written.
+	modTime time.Time
+	size    int64
+}
+
+// NewFileBackend creates a backend persisted at path, loading existing entries.
+// The parent directory is created if needed.
+func NewFileBackend(path string) (*FileBackend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	b := &FileBackend{
+		path:    path,
+		entries: make(map[string]*Entry),
+		memOnly: make(map[string]struct{}),
+		changes: make(map[string]*BackendChange),
+	}
+
+	err := b.withLock(false, func() error {
+		b.mergeLocked(b.readLocked())
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The Cache indexes the initial entries itself.
+	clear(b.changes)
+
+	return b, nil
+}
+
+// NewFileBackendFor creates a backend persisted in the global directory of cfg.
+// Example: NewFileBackendFor(cfg, "issues") uses ~/.valksor/mehrhof/cache/issues.json.
+func NewFileBackendFor(cfg *paths.Config, name string) (*FileBackend, error) {
+	dir, err := cfg.GlobalDir()
+	if err != nil {
+		return nil, fmt.Errorf("resolving global directory: %w", err)
+	}
+
+	return NewFileBackend(filepath.Join(dir, "cache", name+".json"))
+}
+
+// Path returns the path of the cache file.
+func (b *FileBackend) Path() string {
+	return b.path
+}
+
+func (b *FileBackend) Load(key string) (*Entry, bool) {
+	b.mu.RLock()
+	e, ok := b.entries[key]
+	b.mu.RUnlock()
+
+	if ok || !b.changedOnDisk() {
+		return e, ok
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reloadLocked()
+	e, ok = b.entries[key]
+
+	return e, ok
+}
+
+// Sync reloads the file if another process has changed it and returns the
+// entries added, replaced or removed by merging the file since the last call.
+func (b *FileBackend) Sync() []BackendChange {
+	changed := b.changedOnDisk()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if changed {
+		b.reloadLocked()
+	}
+
+	if len(b.changes) == 0 {
+		return nil
+	}
+
+	changes := make([]BackendChange, 0, len(b.changes))
+	for _, ch := range b.changes {
+		changes = append(changes, *ch)
+	}
+	clear(b.changes)
+
+	return changes
+}
+
+func (b *FileBackend) Store(key string, e *Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[key] = e
+
+	raw, err := marshalEntry(e)
+	if err != nil {
+		b.memOnly[key] = struct{}{}
+		b.writeLocked(func(d *fileData) { delete(d.Entries, key) })
+
+		return
+	}
+
+	delete(b.memOnly, key)
+	b.writeLocked(func(d *fileData) {
+		d.Entries[key] = fileEntry{Value: raw, ExpiresAt: e.ExpiresAt}
+	})
+}
+
+func (b *FileBackend) Delete(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.entries, key)
+	delete(b.memOnly, key)
+	b.writeLocked(func(d *fileData) { delete(d.Entries, key) })
+}
+
+func (b *FileBackend) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.entries)
+}
+
+func (b *FileBackend) Range(fn func(key string, e *Entry) bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for key, e := range b.entries {
+		if !fn(key, e) {
+			return
+		}
+	}
+}
+
+func (b *FileBackend) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries = make(map[string]*Entry)
+	b.memOnly = make(map[string]struct{})
+	clear(b.changes)
+	b.writeLocked(func(d *fileData) { d.Entries = make(map[string]fileEntry) })
+}
+
+// marshalEntry encodes the persistable part of e.
+func marshalEntry(e *Entry) (json.RawMessage, error) {
+	if e.err != nil {
+		return nil, fmt.Errorf("negative entries are not persisted: %w", e.err)
+	}
+
+	return json.Marshal(e.Value)
+}
+
+// changedOnDisk returns true if the cache file differs from the version last seen.
+func (b *FileBackend) changedOnDisk() bool {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return false
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return !info.ModTime().Equal(b.modTime) || info.Size() != b.size
+}
+
+// reloadLocked merges the current file contents. Must be called with b.mu
+// held for writing.
+func (b *FileBackend) reloadLocked() {
+	err := b.withLock(false, func() error {
+		b.mergeLocked(b.readLocked())
+
+		return nil
+	})
+	if err != nil {
+		log.Warn("cache: reloading cache file failed", "path", b.path, log.Err(err))
+	}
+}
+
+// readLocked reads the cache file. Missing or corrupt files yield empty data.
+// Must be called with the file lock held.
+func (b *FileBackend) readLocked() *fileData {
+	d := &fileData{Entries: make(map[string]fileEntry)}
+
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("cache: reading cache file failed", "path", b.path, log.Err(err))
+		}
+
+		return d
+	}
+
+	if info, err := os.Stat(b.path); err == nil {
+		b.modTime = info.ModTime()
+		b.size = info.Size()
+	}
+
+	if err := json.Unmarshal(data, d); err != nil {
+		log.Warn("cache: ignoring corrupt cache file", "path", b.path, log.Err(err))
+
+		return &fileData{Entries: make(map[string]fileEntry)}
+	}
+
+	if d.Entries == nil {
+		d.Entries = make(map[string]fileEntry)
+	}
+
+	return d
+}
+
+// mergeLocked replaces the in-memory entries with the file contents, keeping
+// in-memory values that match the file (so they keep their original type)
+// and memory-only entries. Entries that change are recorded for Sync.
+// Must be called with b.mu held for writing.
+func (b *FileBackend) mergeLocked(d *fileData) {
+	now := time.Now()
+	next := make(map[string]*Entry, len(d.Entries)+len(b.memOnly))
+
+	for key, fe := range d.Entries {
+		if now.After(fe.ExpiresAt) {
+			continue
+		}
+
+		if cur, ok := b.entries[key]; ok && cur.ExpiresAt.Equal(fe.ExpiresAt) {
+			next[key] = cur
+
+			continue
+		}
+
+		next[key] = &Entry{Value: fe.Value, ExpiresAt: fe.ExpiresAt}
+	}
+
+	for key := range b.memOnly {
+		if cur, ok := b.entries[key]; ok {
+			next[key] = cur
+		}
+	}
+
+	for key, cur := range b.entries {
+		if _, ok := next[key]; !ok {
+			b.recordLocked(key, cur, nil)
+		}
+	}
+	for key, e := range next {
+		if cur := b.entries[key]; cur != e {
+			b.recordLocked(key, cur, e)
+		}
+	}
+
+	b.entries = next
+}
+
+// recordLocked records that the entry under key changed from old to e,
+// folding it into any change not yet returned by Sync.
+// Must be called with b.mu held for writing.
+func (b *FileBackend) recordLocked(key string, old, e *Entry) {
+	ch, ok := b.changes[key]
+	if !ok {
+		b.changes[key] = &BackendChange{Key: key, Old: old, New: e}
+
+		return
+	}
+
+	ch.New = e
+	if ch.Old == ch.New {
+		delete(b.changes, key)
+	}
+}
+
+// writeLocked applies mutate to the current file contents and writes the
+// result atomically. Failures are logged: the in-memory state stays valid.
+// Must be called with b.mu held for writing.
+func (b *FileBackend) writeLocked(mutate func(d *fileData)) {
+	err := b.withLock(true, func() error {
+		d := b.readLocked()
+		mutate(d)
+
+		// Drop expired entries so the file does not grow without bound
+		now := time.Now()
+		for key, fe := range d.Entries {
+			if now.After(fe.ExpiresAt) {
+				delete(d.Entries, key)
+			}
+		}
+
+		// Pick up entries written by other processes
+		b.mergeLocked(d)
+
+		return b.writeFile(d)
+	})
+	if err != nil {
+		log.Warn("cache: writing cache file failed", "path", b.path, log.Err(err))
+	}
+}
+
+// writeFile atomically replaces the cache file with d.
+func (b *FileBackend) writeFile(d *fileData) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), b.path); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(b.path); err == nil {
+		b.modTime = info.ModTime()
+		b.size = info.Size()
+	}
+
+	return nil
+}
+
+// withLock runs fn while holding the inter-process lock on the cache file.
+func (b *FileBackend) withLock(exclusive bool, fn func() error) error {
+	f, err := os.OpenFile(b.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening cache lock file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := lockFile(f, exclusive); err != nil {
+		return fmt.Errorf("locking cache file: %w", err)
+	}
+	defer func() { _ = unlockFile(f) }()
+
+	return fn()
+}
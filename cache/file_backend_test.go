@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-toolkit/paths"
+)
+
+func newTestFileBackend(t *testing.T, path string) *FileBackend {
+	t.Helper()
+
+	b, err := NewFileBackend(path)
+	if err != nil {
+		t.Fatalf("NewFileBackend: %v", err)
+	}
+
+	return b
+}
+
+func TestFileBackend_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c := New(WithBackend(newTestFileBackend(t, path)))
+	c.Set("issue:1", map[string]any{"title": "bug"}, time.Minute)
+	c.Set("short", "gone", time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+
+	// A new process starts warm
+	c2 := New(WithBackend(newTestFileBackend(t, path)))
+
+	val, ok := c2.Get("issue:1")
+	if !ok {
+		t.Fatal("expected issue:1 to be loaded from disk")
+	}
+	raw, ok := val.(json.RawMessage)
+	if !ok {
+		t.Fatalf("expected json.RawMessage, got %T", val)
+	}
+	if string(raw) != `{"title":"bug"}` {
+		t.Fatalf("unexpected value %s", raw)
+	}
+
+	if _, ok := c2.Get("short"); ok {
+		t.Fatal("expected expired entry not to be loaded")
+	}
+	if c2.Size() != 1 {
+		t.Fatalf("expected size 1, got %d", c2.Size())
+	}
+}
+
+func TestFileBackend_TypedDecodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "issues.json")
+
+	issues := NewTyped[int, *testIssue](DefaultIssueTTL, WithBackend(newTestFileBackend(t, path)))
+	issues.Set(1, &testIssue{ID: 1, Title: "bug"})
+
+	// Same process keeps the original value
+	issue, ok := issues.Get(1)
+	if !ok || issue.Title != "bug" {
+		t.Fatalf("expected original issue, got %+v (ok=%v)", issue, ok)
+	}
+
+	reloaded := NewTyped[int, *testIssue](DefaultIssueTTL, WithBackend(newTestFileBackend(t, path)))
+	issue, ok = reloaded.Get(1)
+	if !ok {
+		t.Fatal("expected issue to be decoded from disk")
+	}
+	if issue.ID != 1 || issue.Title != "bug" {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestFileBackend_SharedBetweenProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	a := New(WithBackend(newTestFileBackend(t, path)))
+	b := New(WithBackend(newTestFileBackend(t, path)))
+
+	a.Set("from-a", "a", time.Minute)
+
+	// b misses in memory and reloads the changed file
+	if _, ok := b.Get("from-a"); !ok {
+		t.Fatal("expected b to see a's entry")
+	}
+
+	b.Set("from-b", "b", time.Minute)
+	b.Delete("from-a")
+
+	// a's next write merges with b's changes
+	a.Set("other", "x", time.Minute)
+
+	fresh := New(WithBackend(newTestFileBackend(t, path)))
+	if _, ok := fresh.Get("from-a"); ok {
+		t.Fatal("expected from-a to stay deleted")
+	}
+	if _, ok := fresh.Get("from-b"); !ok {
+		t.Fatal("expected from-b to be persisted")
+	}
+	if _, ok := fresh.Get("other"); !ok {
+		t.Fatal("expected other to be persisted")
+	}
+}
+
+func TestFileBackend_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		c := New(WithBackend(newTestFileBackend(t, path)))
+		wg.Go(func() {
+			for j := range 10 {
+				c.Set(string(rune('a'+i))+string(rune('0'+j)), j, time.Minute)
+			}
+		})
+	}
+	wg.Wait()
+
+	fresh := New(WithBackend(newTestFileBackend(t, path)))
+	if fresh.Size() != 40 {
+		t.Fatalf("expected 40 entries from 4 writers, got %d", fresh.Size())
+	}
+}
+
+func TestFileBackend_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(WithBackend(newTestFileBackend(t, path)))
+	if c.Size() != 0 {
+		t.Fatalf("expected empty cache from corrupt file, got %d", c.Size())
+	}
+
+	c.Set("key", "value", time.Minute)
+
+	fresh := New(WithBackend(newTestFileBackend(t, path)))
+	if _, ok := fresh.Get("key"); !ok {
+		t.Fatal("expected corrupt file to be replaced on write")
+	}
+}
+
+func TestFileBackend_UnpersistableValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c := New(WithBackend(newTestFileBackend(t, path)))
+	c.Set("func", func() {}, time.Minute)
+
+	if _, ok := c.Get("func"); !ok {
+		t.Fatal("expected unmarshalable value to be kept in memory")
+	}
+
+	// A write triggered by another key keeps memory-only entries
+	c.Set("other", "x", time.Minute)
+	if _, ok := c.Get("func"); !ok {
+		t.Fatal("expected memory-only entry to survive a write")
+	}
+
+	fresh := New(WithBackend(newTestFileBackend(t, path)))
+	if _, ok := fresh.Get("func"); ok {
+		t.Fatal("expected unmarshalable value not to be persisted")
+	}
+}
+
+func TestFileBackend_Clear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c := New(WithBackend(newTestFileBackend(t, path)))
+	c.Set("a", 1, time.Minute)
+	c.Clear()
+
+	fresh := New(WithBackend(newTestFileBackend(t, path)))
+	if fresh.Size() != 0 {
+		t.Fatalf("expected empty cache after clear, got %d", fresh.Size())
+	}
+}
+
+func TestFileBackend_BoundedReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c := New(WithBackend(newTestFileBackend(t, path)))
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+
+	// Existing entries count towards the bound of a new cache
+	bounded := New(WithBackend(newTestFileBackend(t, path)), WithMaxEntries(2))
+	bounded.Set("c", 3, time.Minute)
+
+	if bounded.Size() != 2 {
+		t.Fatalf("expected size 2, got %d", bounded.Size())
+	}
+	if bounded.Evictions() != 1 {
+		t.Fatalf("expected 1 eviction, got %d", bounded.Evictions())
+	}
+}
+
+func TestFileBackend_SharedBounded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	sizeOf := func(string, any) int64 { return 1 }
+
+	a := New(WithBackend(newTestFileBackend(t, path)), WithMaxEntries(2), WithMaxBytes(10, sizeOf))
+	b := New(WithBackend(newTestFileBackend(t, path)), WithMaxEntries(2), WithMaxBytes(10, sizeOf))
+
+	a.Set("issue:1", 1, time.Minute)
+	a.Set("issue:2", 2, time.Minute)
+	b.Set("issue:3", 3, time.Minute)
+	b.Set("issue:4", 4, time.Minute)
+
+	for name, c := range map[string]*Cache{"a": a, "b": b} {
+		size := c.Size()
+		if size > 2 {
+			t.Errorf("%s: expected at most 2 entries, got %d", name, size)
+		}
+		if got := c.Bytes(); got != int64(size) {
+			t.Errorf("%s: expected %d bytes for %d entries, got %d", name, size, size, got)
+		}
+	}
+
+	// Both caches keep working within bounds after further writes
+	a.Set("issue:5", 5, time.Minute)
+	if size := a.Size(); size != 2 {
+		t.Fatalf("expected size 2, got %d", size)
+	}
+	if size := b.Size(); size > 2 {
+		t.Fatalf("expected at most 2 entries in b, got %d", size)
+	}
+}
+
+func TestFileBackend_SharedDeletePrefix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	a := New(WithBackend(newTestFileBackend(t, path)))
+	b := New(WithBackend(newTestFileBackend(t, path)))
+
+	a.Set("issue:1", 1, time.Minute)
+	b.Set("issue:2", 2, time.Minute)
+	b.Set("issue:3", 3, time.Minute)
+	b.Set("pr:1", 1, time.Minute)
+
+	if n := a.DeletePrefix("issue:"); n != 3 {
+		t.Fatalf("expected 3 keys deleted, got %d", n)
+	}
+	if size := a.Size(); size != 1 {
+		t.Fatalf("expected only pr:1 to remain in a, got %d entries", size)
+	}
+	if size := b.Size(); size != 1 {
+		t.Fatalf("expected only pr:1 to remain in b, got %d entries", size)
+	}
+	if _, ok := b.Get("issue:2"); ok {
+		t.Fatal("expected issue:2 to be deleted for b as well")
+	}
+}
+
+func TestNewFileBackendFor(t *testing.T) {
+	home := t.TempDir()
+	defer paths.SetHomeDirForTesting(home)()
+
+	cfg := &paths.Config{Vendor: ".valksor", ToolName: "mehrhof"}
+
+	b, err := NewFileBackendFor(cfg, "issues")
+	if err != nil {
+		t.Fatalf("NewFileBackendFor: %v", err)
+	}
+
+	expected := filepath.Join(home, ".valksor", "mehrhof", "cache", "issues.json")
+	if b.Path() != expected {
+		t.Fatalf("expected path %s, got %s", expected, b.Path())
+	}
+
+	New(WithBackend(b)).Set("key", "value", time.Minute)
+
+	if !paths.FileExists(expected) {
+		t.Fatal("expected cache file to be written")
+	}
+}
@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// KeySeparator separates key segments, e.g. "issue:123".
+// Namespaces and DeletePrefix use it to index keys hierarchically.
+const KeySeparator = ":"
+
+// keyNode is a node of the key index: a trie over KeySeparator-delimited segments.
+type keyNode struct {
+	children map[string]*keyNode
+	// terminal is true if a key ends at this node.
+	terminal bool
+}
+
+// keyIndex indexes keys by segment so keys sharing a prefix can be found
+// without scanning every key.
+type keyIndex struct {
+	root *keyNode
+}
+
+func newKeyIndex() *keyIndex {
+	return &keyIndex{root: &keyNode{}}
+}
+
+func (idx *keyIndex) add(key string) {
+	node := idx.root
+	for _, seg := range strings.Split(key, KeySeparator) {
+		child, ok := node.children[seg]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*keyNode)
+			}
+			child = &keyNode{}
+			node.children[seg] = child
+		}
+		node = child
+	}
+	node.terminal = true
+}
+
+func (idx *keyIndex) remove(key string) {
+	segs := strings.Split(key, KeySeparator)
+	path := make([]*keyNode, 0, len(segs)+1)
+
+	node := idx.root
+	path = append(path, node)
+	for _, seg := range segs {
+		child, ok := node.children[seg]
+		if !ok {
+			return
+		}
+		node = child
+		path = append(path, node)
+	}
+	node.terminal = false
+
+	// Prune nodes that no longer lead to any key
+	for i := len(segs) - 1; i >= 0; i-- {
+		child := path[i+1]
+		if child.terminal || len(child.children) > 0 {
+			return
+		}
+		delete(path[i].children, segs[i])
+	}
+}
+
+// withPrefix returns all indexed keys starting with prefix. Only the subtrees
+// matching the prefix are visited.
+func (idx *keyIndex) withPrefix(prefix string) []string {
+	segs := strings.Split(prefix, KeySeparator)
+	last := segs[len(segs)-1]
+
+	node := idx.root
+	for _, seg := range segs[:len(segs)-1] {
+		child, ok := node.children[seg]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	base := strings.Join(segs[:len(segs)-1], KeySeparator)
+
+	var keys []string
+	for seg, child := range node.children {
+		if !strings.HasPrefix(seg, last) {
+			continue
+		}
+
+		key := seg
+		if len(segs) > 1 {
+			key = base + KeySeparator + seg
+		}
+		keys = collectKeys(child, key, keys)
+	}
+
+	return keys
+}
+
+// collectKeys appends key and all keys below node.
+func collectKeys(node *keyNode, key string, keys []string) []string {
+	if node.terminal {
+		keys = append(keys, key)
+	}
+	for seg, child := range node.children {
+		keys = collectKeys(child, key+KeySeparator+seg, keys)
+	}
+
+	return keys
+}
+
+// indexLocked records key and its tags. Must be called with c.mu held.
+func (c *Cache) indexLocked(key string, e *Entry) {
+	c.keys.add(key)
+
+	for _, tag := range e.tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// unindexLocked forgets key and its tags. Must be called with c.mu held.
+func (c *Cache) unindexLocked(key string, e *Entry) {
+	c.keys.remove(key)
+
+	for _, tag := range e.tags {
+		keys := c.tags[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// SetWithTags stores a value with the given TTL and associates it with tags,
+// so it can be removed later with InvalidateTag. Storing the key again
+// replaces its tags.
+func (c *Cache) SetWithTags(key string, data any, ttl time.Duration, tags ...string) {
+	c.set(key, &Entry{Value: data, ExpiresAt: time.Now().Add(ttl), tags: slices.Clone(tags)})
+}
+
+// InvalidateTag removes every entry tagged with tag and returns how many were removed.
+// Loads and refreshes of the removed keys in flight are not stored when they complete.
+func (c *Cache) InvalidateTag(tag string) int {
+	c.loadMu.Lock()
+	c.mu.Lock()
+	c.syncLocked()
+	keys := make([]string, 0, len(c.tags[tag]))
+	for key := range c.tags[tag] {
+		keys = append(keys, key)
+	}
+
+	return c.deleteKeysAndUnlock(keys)
+}
+
+// DeletePrefix removes every entry whose key starts with prefix and returns
+// how many were removed. Keys are indexed by KeySeparator segments, so only
+// keys under the matching segments are visited. Loads and refreshes of
+// matching keys in flight are not stored when they complete.
+func (c *Cache) DeletePrefix(prefix string) int {
+	c.loadMu.Lock()
+	for key := range c.calls {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.calls, key)
+		}
+	}
+
+	c.mu.Lock()
+	c.syncLocked()
+
+	return c.deleteKeysAndUnlock(c.keys.withPrefix(prefix))
+}
+
+// deleteKeysAndUnlock removes keys and forgets their in-flight loads, releases
+// c.mu and c.loadMu and then publishes delete events. Must be called with
+// c.loadMu and c.mu held.
+func (c *Cache) deleteKeysAndUnlock(keys []string) int {
+	removed := keys[:0]
+	for _, key := range keys {
+		delete(c.calls, key)
+		if c.removeLocked(key) {
+			removed = append(removed, key)
+		}
+	}
+	c.mu.Unlock()
+	c.loadMu.Unlock()
+
+	c.stats.deletes.Add(uint64(len(removed)))
+	for _, key := range removed {
+		c.emit(EventDelete, key)
+	}
+
+	return len(removed)
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valksor/go-toolkit/errors"
+)
+
+// LoaderFunc loads the value for a key on a cache miss.
+type LoaderFunc func(ctx context.Context) (any, error)
+
+// call is an in-flight or completed GetOrLoad invocation.
+type call struct {
+	done    chan struct{}
+	val     any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// WithNegativeCaching caches loader errors for ttl so repeated lookups of a
+// missing resource do not hit the provider again. Only errors for which match
+// returns true are cached; a nil match caches errors wrapping errors.ErrNotFound.
+func WithNegativeCaching(ttl time.Duration, match func(error) bool) Option {
+	return func(o *options) {
+		if ttl <= 0 {
+			return
+		}
+		if match == nil {
+			match = errors.IsNotFound
+		}
+		o.negativeTTL = ttl
+		o.isNegative = match
+	}
+}
+
+// GetOrLoad returns the cached value for key, calling loader on a miss and
+// caching its result for ttl.
+//
+// Concurrent calls for the same key share a single loader invocation. If ctx
+// is done before the load completes, GetOrLoad returns ctx.Err(); the loader
+// keeps running for the remaining callers and its context is cancelled only
+// once every caller has given up.
+//
+// Loader errors are returned as-is and not cached, unless negative caching is
+// enabled with WithNegativeCaching and the error matches. When the cache is
+// disabled, loader is called directly.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoaderFunc) (any, error) {
+	if !c.Enabled() {
+		return loader(ctx)
+	}
+
+	if e, ok := c.lookup(key); ok {
+		return e.Value, e.err
+	}
+
+	c.loadMu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = cl
+
+		go c.load(loadCtx, key, loader, cl, func(val any, err error) *Entry {
+			switch {
+			case err == nil:
+				return &Entry{Value: val, ExpiresAt: time.Now().Add(ttl)}
+			case c.isNegative != nil && c.isNegative(err):
+				return &Entry{err: err, ExpiresAt: time.Now().Add(c.negativeTTL)}
+			default:
+				return nil
+			}
+		})
+	}
+	cl.waiters++
+	c.loadMu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.val, cl.err
+	case <-ctx.Done():
+		c.loadMu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			// Nobody is waiting anymore: abandon the load so a later
+			// caller starts a fresh one instead of joining a cancelled call.
+			cl.cancel()
+			if c.calls[key] == cl {
+				delete(c.calls, key)
+			}
+		}
+		c.loadMu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// load runs loader for key and publishes the result to waiters of cl.
+// Unless the call was abandoned, commit is called with the result under
+// loadMu and the entry it returns, if any, is stored. Events are published
+// after loadMu is released, so handlers may call back into the cache.
+func (c *Cache) load(ctx context.Context, key string, loader LoaderFunc, cl *call, commit func(val any, err error) *Entry) {
+	defer cl.cancel()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				cl.err = fmt.Errorf("cache: loader for %q panicked: %v", key, r)
+			}
+		}()
+		cl.val, cl.err = loader(ctx)
+	}()
+
+	var (
+		evicted []string
+		stored  bool
+	)
+
+	c.loadMu.Lock()
+	// Only store the result if the call was neither abandoned nor invalidated
+	// by Delete, DeletePrefix, InvalidateTag or Clear; otherwise the value may
+	// be stale, or a newer load may already have stored a fresher one.
+	if c.calls[key] == cl {
+		delete(c.calls, key)
+		if e := commit(cl.val, cl.err); e != nil {
+			evicted, stored = c.store(key, e)
+		}
+	}
+	c.loadMu.Unlock()
+
+	if stored {
+		c.emitSet(key, evicted)
+	}
+
+	close(cl.done)
+}
+
+// GetOrLoad returns the cached value for key, calling loader on a miss and
+// caching its result with the default TTL. See Cache.GetOrLoad.
+func (t *Typed[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	val, err := t.cache.GetOrLoad(ctx, typedKey(key), t.ttl, func(ctx context.Context) (any, error) {
+		return loader(ctx)
+	})
+
+	v, _ := asTyped[V](val)
+
+	return v, err
+}
@@ -0,0 +1,332 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	providererrors "github.com/valksor/go-toolkit/errors"
+)
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c := New()
+	calls := 0
+
+	loader := func(_ context.Context) (any, error) {
+		calls++
+
+		return "loaded", nil
+	}
+
+	val, err := c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if val != "loaded" {
+		t.Fatalf("expected 'loaded', got %v", val)
+	}
+
+	// Second call is served from cache
+	if _, err := c.GetOrLoad(context.Background(), "key", time.Minute, loader); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected loader to be called once, got %d", calls)
+	}
+
+	if v, ok := c.Get("key"); !ok || v != "loaded" {
+		t.Fatalf("expected cached value, got %v (ok=%v)", v, ok)
+	}
+}
+
+func TestCache_GetOrLoadCoalesces(t *testing.T) {
+	c := New()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	loader := func(_ context.Context) (any, error) {
+		calls.Add(1)
+		<-release
+
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]any, 10)
+	for i := range results {
+		wg.Go(func() {
+			val, err := c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results[i] = val
+		})
+	}
+
+	// Give goroutines time to join the in-flight call
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 loader call, got %d", calls.Load())
+	}
+	for i, r := range results {
+		if r != 42 {
+			t.Fatalf("result %d: expected 42, got %v", i, r)
+		}
+	}
+}
+
+func TestCache_GetOrLoadErrorNotCached(t *testing.T) {
+	c := New()
+	loadErr := errors.New("boom")
+	calls := 0
+
+	loader := func(_ context.Context) (any, error) {
+		calls++
+
+		return nil, loadErr
+	}
+
+	for range 2 {
+		_, err := c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+		if !errors.Is(err, loadErr) {
+			t.Fatalf("expected loadErr, got %v", err)
+		}
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected errors not to be cached, got %d calls", calls)
+	}
+}
+
+func TestCache_GetOrLoadNegativeCaching(t *testing.T) {
+	c := New(WithNegativeCaching(time.Minute, nil))
+	calls := 0
+
+	loader := func(_ context.Context) (any, error) {
+		calls++
+
+		return nil, providererrors.NotFoundError("github", "issue 123")
+	}
+
+	for range 3 {
+		_, err := c.GetOrLoad(context.Background(), "issue:123", time.Minute, loader)
+		if !providererrors.IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected negative result to be cached, got %d calls", calls)
+	}
+
+	// Negative entries are misses for plain Get
+	if _, ok := c.Get("issue:123"); ok {
+		t.Fatal("expected Get to miss negative entry")
+	}
+}
+
+func TestCache_GetOrLoadNegativeTTL(t *testing.T) {
+	c := New(WithNegativeCaching(10*time.Millisecond, nil))
+	calls := 0
+
+	loader := func(_ context.Context) (any, error) {
+		calls++
+
+		return nil, providererrors.ErrNotFound
+	}
+
+	_, _ = c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+	time.Sleep(20 * time.Millisecond)
+	_, _ = c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+
+	if calls != 2 {
+		t.Fatalf("expected negative entry to expire, got %d calls", calls)
+	}
+}
+
+func TestCache_GetOrLoadContextCancel(t *testing.T) {
+	c := New()
+
+	started := make(chan struct{})
+	loaderDone := make(chan error, 1)
+
+	loader := func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		loaderDone <- ctx.Err()
+
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(ctx, "key", time.Minute, loader)
+		errCh <- err
+	}()
+
+	<-started
+	cancel()
+
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The only waiter left, so the loader context is cancelled too
+	select {
+	case err := <-loaderDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected loader context to be cancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("loader context was not cancelled")
+	}
+
+	// A later call starts a fresh load
+	val, err := c.GetOrLoad(context.Background(), "key", time.Minute, func(_ context.Context) (any, error) {
+		return "fresh", nil
+	})
+	if err != nil || val != "fresh" {
+		t.Fatalf("expected fresh load, got %v, %v", val, err)
+	}
+}
+
+func TestCache_GetOrLoadWaiterCancelKeepsLoad(t *testing.T) {
+	c := New()
+	release := make(chan struct{})
+
+	loader := func(ctx context.Context) (any, error) {
+		select {
+		case <-release:
+			return "value", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var wg sync.WaitGroup
+	var patientErr error
+	wg.Go(func() {
+		_, patientErr = c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+	})
+
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetOrLoad(ctx, "key", time.Minute, loader); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if patientErr != nil {
+		t.Fatalf("expected remaining waiter to succeed, got %v", patientErr)
+	}
+	if v, ok := c.Get("key"); !ok || v != "value" {
+		t.Fatalf("expected value to be cached, got %v (ok=%v)", v, ok)
+	}
+}
+
+func TestCache_GetOrLoadInvalidatedWhileLoading(t *testing.T) {
+	invalidations := map[string]func(c *Cache){
+		"Delete":       func(c *Cache) { c.Delete("issue:1") },
+		"DeletePrefix": func(c *Cache) { c.DeletePrefix("issue:") },
+		"Clear":        func(c *Cache) { c.Clear() },
+	}
+
+	for name, invalidate := range invalidations {
+		t.Run(name, func(t *testing.T) {
+			c := New()
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			var wg sync.WaitGroup
+			var val any
+			wg.Go(func() {
+				val, _ = c.GetOrLoad(context.Background(), "issue:1", time.Minute, func(context.Context) (any, error) {
+					close(started)
+					<-release
+
+					return "stale", nil
+				})
+			})
+
+			<-started
+			invalidate(c)
+			close(release)
+			wg.Wait()
+
+			if val != "stale" {
+				t.Fatalf("expected the waiter to still get the loaded value, got %v", val)
+			}
+			if v, ok := c.Get("issue:1"); ok {
+				t.Fatalf("expected value loaded before the invalidation not to be stored, got %v", v)
+			}
+
+			// The next read loads again
+			v, err := c.GetOrLoad(context.Background(), "issue:1", time.Minute, func(context.Context) (any, error) {
+				return "fresh", nil
+			})
+			if err != nil || v != "fresh" {
+				t.Fatalf("expected fresh load, got %v, %v", v, err)
+			}
+		})
+	}
+}
+
+func TestCache_GetOrLoadPanic(t *testing.T) {
+	c := New()
+
+	_, err := c.GetOrLoad(context.Background(), "key", time.Minute, func(_ context.Context) (any, error) {
+		panic("loader exploded")
+	})
+	if err == nil {
+		t.Fatal("expected error from panicking loader")
+	}
+}
+
+func TestCache_GetOrLoadDisabled(t *testing.T) {
+	c := New()
+	c.Disable()
+	calls := 0
+
+	for range 2 {
+		_, _ = c.GetOrLoad(context.Background(), "key", time.Minute, func(_ context.Context) (any, error) {
+			calls++
+
+			return "v", nil
+		})
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected loader to be called on every call when disabled, got %d", calls)
+	}
+}
+
+func TestTyped_GetOrLoad(t *testing.T) {
+	c := NewTyped[int, *testIssue](DefaultIssueTTL)
+
+	issue, err := c.GetOrLoad(context.Background(), 7, func(_ context.Context) (*testIssue, error) {
+		return &testIssue{ID: 7}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.ID != 7 {
+		t.Fatalf("expected ID 7, got %d", issue.ID)
+	}
+
+	cached, ok := c.Get(7)
+	if !ok || cached != issue {
+		t.Fatal("expected loaded issue to be cached")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package cache
+
+import "os"
+
+// lockFile is a no-op on platforms without flock. Writes are still atomic
+// (temp file + rename), but concurrent processes may lose each other's updates.
+func lockFile(_ *os.File, _ bool) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock.
+func unlockFile(_ *os.File) error {
+	return nil
+}
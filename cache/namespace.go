@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Namespace is a view of a Cache restricted to keys under a common prefix,
+// with its own default TTL.
+//
+// Keys passed to a Namespace are relative: in the namespace "issue",
+// key "123" is stored as "issue:123" in the underlying cache.
+//
+// Usage:
+//
+//	issues := c.Namespace("issue", cache.DefaultIssueTTL)
+//	issues.Set("123", issue)
+//	issues.Clear() // removes every "issue:*" key
+type Namespace struct {
+	cache  *Cache
+	prefix string
+	ttl    time.Duration
+}
+
+// Namespace returns a view of the cache for keys under name + KeySeparator.
+func (c *Cache) Namespace(name string, defaultTTL time.Duration) *Namespace {
+	return &Namespace{
+		cache:  c,
+		prefix: name + KeySeparator,
+		ttl:    defaultTTL,
+	}
+}
+
+// Namespace returns a nested namespace, e.g. "github" within "issue" is "issue:github".
+// A zero defaultTTL inherits the parent's default TTL.
+func (n *Namespace) Namespace(name string, defaultTTL time.Duration) *Namespace {
+	if defaultTTL == 0 {
+		defaultTTL = n.ttl
+	}
+
+	return &Namespace{
+		cache:  n.cache,
+		prefix: n.prefix + name + KeySeparator,
+		ttl:    defaultTTL,
+	}
+}
+
+// Key returns the full cache key for a key in this namespace.
+func (n *Namespace) Key(key string) string {
+	return n.prefix + key
+}
+
+// Prefix returns the key prefix of this namespace, including the trailing separator.
+func (n *Namespace) Prefix() string {
+	return n.prefix
+}
+
+// TTL returns the default TTL of this namespace.
+func (n *Namespace) TTL() time.Duration {
+	return n.ttl
+}
+
+// Get retrieves a value by key. See Cache.Get.
+func (n *Namespace) Get(key string) (any, bool) {
+	return n.cache.Get(n.Key(key))
+}
+
+// Set stores a value with the namespace's default TTL.
+func (n *Namespace) Set(key string, data any) {
+	n.cache.Set(n.Key(key), data, n.ttl)
+}
+
+// SetWithTTL stores a value with an explicit TTL.
+func (n *Namespace) SetWithTTL(key string, data any, ttl time.Duration) {
+	n.cache.Set(n.Key(key), data, ttl)
+}
+
+// SetWithTags stores a value with the namespace's default TTL and the given tags.
+func (n *Namespace) SetWithTags(key string, data any, tags ...string) {
+	n.cache.SetWithTags(n.Key(key), data, n.ttl, tags...)
+}
+
+// GetOrLoad returns the cached value for key or loads it with the namespace's
+// default TTL. See Cache.GetOrLoad.
+func (n *Namespace) GetOrLoad(ctx context.Context, key string, loader LoaderFunc) (any, error) {
+	return n.cache.GetOrLoad(ctx, n.Key(key), n.ttl, loader)
+}
+
+// Delete removes a value from the namespace.
+func (n *Namespace) Delete(key string) {
+	n.cache.Delete(n.Key(key))
+}
+
+// Clear removes every entry in the namespace and returns how many were removed.
+func (n *Namespace) Clear() int {
+	return n.cache.DeletePrefix(n.prefix)
+}
@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCache_DeletePrefix(t *testing.T) {
+	c := New()
+
+	c.Set("issue:1", 1, time.Minute)
+	c.Set("issue:12", 12, time.Minute)
+	c.Set("issue:2", 2, time.Minute)
+	c.Set("issue", 0, time.Minute)
+	c.Set("comments:1", 1, time.Minute)
+	c.Set("issues:1", 1, time.Minute)
+
+	if n := c.DeletePrefix("issue:1"); n != 2 {
+		t.Fatalf("expected 2 keys removed, got %d", n)
+	}
+	if _, ok := c.Get("issue:2"); !ok {
+		t.Fatal("expected issue:2 to remain")
+	}
+
+	if n := c.DeletePrefix("issue:"); n != 1 {
+		t.Fatalf("expected 1 key removed, got %d", n)
+	}
+	if _, ok := c.Get("issue"); !ok {
+		t.Fatal("expected key 'issue' not to match prefix 'issue:'")
+	}
+
+	// Prefixes need not end at a separator
+	if n := c.DeletePrefix("iss"); n != 2 {
+		t.Fatalf("expected 2 keys removed, got %d", n)
+	}
+
+	if c.Size() != 1 {
+		t.Fatalf("expected only comments:1 to remain, got size %d", c.Size())
+	}
+
+	if n := c.DeletePrefix(""); n != 1 {
+		t.Fatalf("expected empty prefix to match everything, got %d", n)
+	}
+}
+
+func TestKeyIndex(t *testing.T) {
+	idx := newKeyIndex()
+
+	for _, key := range []string{"a:b:c", "a:b", "a:bc", "b", "a::x"} {
+		idx.add(key)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"a:b", []string{"a:b", "a:b:c", "a:bc"}},
+		{"a:b:", []string{"a:b:c"}},
+		{"a:", []string{"a::x", "a:b", "a:b:c", "a:bc"}},
+		{"a::", []string{"a::x"}},
+		{"b", []string{"b"}},
+		{"c", nil},
+	}
+
+	for _, tt := range tests {
+		got := idx.withPrefix(tt.prefix)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("withPrefix(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+
+	idx.remove("a:b:c")
+	idx.remove("a:b")
+	if got := idx.withPrefix("a:b"); !slices.Equal(got, []string{"a:bc"}) {
+		t.Errorf("after remove, withPrefix(a:b) = %v", got)
+	}
+
+	// Removed branches are pruned
+	if _, ok := idx.root.children["a"].children["b"]; ok {
+		t.Error("expected empty node a:b to be pruned")
+	}
+}
+
+func TestCache_InvalidateTag(t *testing.T) {
+	c := New()
+
+	c.SetWithTags("issue:1", 1, time.Minute, "workunit:42", "provider:github")
+	c.SetWithTags("comments:1", 1, time.Minute, "workunit:42")
+	c.SetWithTags("issue:2", 2, time.Minute, "provider:github")
+	c.Set("plain", 0, time.Minute)
+
+	if n := c.InvalidateTag("workunit:42"); n != 2 {
+		t.Fatalf("expected 2 entries invalidated, got %d", n)
+	}
+	if _, ok := c.Get("issue:1"); ok {
+		t.Fatal("expected issue:1 to be invalidated")
+	}
+	if _, ok := c.Get("issue:2"); !ok {
+		t.Fatal("expected issue:2 to remain")
+	}
+
+	// issue:1 is gone, so only issue:2 remains under the provider tag
+	if n := c.InvalidateTag("provider:github"); n != 1 {
+		t.Fatalf("expected 1 entry invalidated, got %d", n)
+	}
+
+	if n := c.InvalidateTag("unknown"); n != 0 {
+		t.Fatalf("expected 0 entries for unknown tag, got %d", n)
+	}
+	if c.Size() != 1 {
+		t.Fatalf("expected size 1, got %d", c.Size())
+	}
+}
+
+func TestCache_SetReplacesTags(t *testing.T) {
+	c := New()
+
+	c.SetWithTags("key", 1, time.Minute, "old")
+	c.SetWithTags("key", 2, time.Minute, "new")
+
+	if n := c.InvalidateTag("old"); n != 0 {
+		t.Fatalf("expected old tag to be dropped, got %d", n)
+	}
+
+	c.Set("key", 3, time.Minute)
+	if n := c.InvalidateTag("new"); n != 0 {
+		t.Fatalf("expected plain Set to drop tags, got %d", n)
+	}
+	if len(c.tags) != 0 {
+		t.Fatalf("expected tag index to be empty, got %v", c.tags)
+	}
+}
+
+func TestCache_IndexesFollowEviction(t *testing.T) {
+	c := New(WithMaxEntries(1))
+
+	c.SetWithTags("issue:1", 1, time.Minute, "t")
+	c.SetWithTags("issue:2", 2, time.Minute, "t") // evicts issue:1
+
+	if n := c.InvalidateTag("t"); n != 1 {
+		t.Fatalf("expected 1 entry invalidated, got %d", n)
+	}
+
+	c.Set("issue:3", 3, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	c.Cleanup()
+
+	if keys := c.keys.withPrefix(""); len(keys) != 0 {
+		t.Fatalf("expected empty key index, got %v", keys)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	c := New()
+	issues := c.Namespace("issue", DefaultIssueTTL)
+	comments := c.Namespace("comments", DefaultCommentsTTL)
+
+	issues.Set("1", "issue one")
+	issues.SetWithTTL("2", "issue two", time.Minute)
+	comments.Set("1", "comment one")
+
+	if v, ok := c.Get("issue:1"); !ok || v != "issue one" {
+		t.Fatalf("expected namespaced key issue:1, got %v (ok=%v)", v, ok)
+	}
+	if v, ok := issues.Get("1"); !ok || v != "issue one" {
+		t.Fatalf("expected issue one, got %v (ok=%v)", v, ok)
+	}
+	if issues.TTL() != DefaultIssueTTL {
+		t.Fatalf("expected TTL %v, got %v", DefaultIssueTTL, issues.TTL())
+	}
+
+	if n := issues.Clear(); n != 2 {
+		t.Fatalf("expected 2 entries cleared, got %d", n)
+	}
+	if _, ok := comments.Get("1"); !ok {
+		t.Fatal("expected other namespace to be untouched")
+	}
+
+	comments.Delete("1")
+	if c.Size() != 0 {
+		t.Fatalf("expected empty cache, got size %d", c.Size())
+	}
+}
+
+func TestNamespace_Nested(t *testing.T) {
+	c := New()
+	github := c.Namespace("issue", DefaultIssueTTL).Namespace("github", 0)
+
+	if github.Key("1") != "issue:github:1" {
+		t.Fatalf("unexpected key %q", github.Key("1"))
+	}
+	if github.TTL() != DefaultIssueTTL {
+		t.Fatalf("expected inherited TTL, got %v", github.TTL())
+	}
+
+	github.SetWithTags("1", 1, "workunit:7")
+	val, err := github.GetOrLoad(context.Background(), "2", func(_ context.Context) (any, error) {
+		return 2, nil
+	})
+	if err != nil || val != 2 {
+		t.Fatalf("expected loaded value 2, got %v, %v", val, err)
+	}
+
+	if n := c.DeletePrefix("issue:"); n != 2 {
+		t.Fatalf("expected 2 entries removed, got %d", n)
+	}
+}
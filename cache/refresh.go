@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultRefreshTimeout bounds a single background refresh.
+	DefaultRefreshTimeout = 30 * time.Second
+	// DefaultRefreshRetryDelay is the wait after a failed background refresh
+	// before reads start another. It doubles with every consecutive failure,
+	// up to the soft TTL of the entry.
+	DefaultRefreshRetryDelay = time.Second
+)
+
+// RefreshFunc reloads the value for key in the background.
+type RefreshFunc func(ctx context.Context, key string) (any, error)
+
+// refresher holds the stale-while-revalidate settings of an entry.
+// It is shared by the entries a refresh replaces, so it also tracks failed
+// refreshes; failures and retryAt are guarded by Cache.loadMu.
+type refresher struct {
+	fn      RefreshFunc
+	softTTL time.Duration
+	hardTTL time.Duration
+
+	failures int
+	retryAt  time.Time
+}
+
+// needsRefresh returns true if reading the entry should trigger a background refresh.
+func (e *Entry) needsRefresh() bool {
+	return e.refresher != nil && !time.Now().Before(e.refreshAt)
+}
+
+// WithRefreshAhead refreshes entries stored with SetWithRefresh once the given
+// fraction of their soft TTL has elapsed, so hot entries are renewed before
+// they ever become stale. Fractions outside (0, 1) disable refresh-ahead.
+func WithRefreshAhead(fraction float64) Option {
+	return func(o *options) {
+		if fraction > 0 && fraction < 1 {
+			o.refreshAhead = fraction
+		}
+	}
+}
+
+// WithRefreshTimeout bounds each background refresh.
+// Defaults to DefaultRefreshTimeout.
+func WithRefreshTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.refreshTimeout = timeout
+		}
+	}
+}
+
+// WithRefreshRetryDelay sets the wait after a failed background refresh
+// before reads start another, so a provider that is down is not called on
+// every read. The delay doubles with every consecutive failure, up to the soft
+// TTL of the entry. Defaults to DefaultRefreshRetryDelay.
+func WithRefreshRetryDelay(delay time.Duration) Option {
+	return func(o *options) {
+		if delay > 0 {
+			o.refreshRetryDelay = delay
+		}
+	}
+}
+
+// WithRefreshErrorHook registers a function called when a background refresh
+// fails. The stale value keeps being served until its hard expiry.
+// The hook runs on the refresh goroutine.
+func WithRefreshErrorHook(hook func(key string, err error)) Option {
+	return func(o *options) {
+		o.onRefreshError = hook
+	}
+}
+
+// SetWithRefresh stores a value in stale-while-revalidate mode.
+//
+// The value is fresh for softTTL. After that, Get keeps returning it until
+// hardTTL while refresh reloads it in the background; the first read of a
+// stale value starts the refresh and concurrent reads do not start another.
+// A hardTTL shorter than softTTL is treated as equal to it.
+//
+// If a refresh fails, the error hook is called and the stale value is kept.
+// Reads start another refresh only once the retry delay has passed (see
+// WithRefreshRetryDelay).
+//
+// Deleting the key stops refreshing it; a refresh in flight when the key is
+// deleted is not stored.
+func (c *Cache) SetWithRefresh(key string, data any, softTTL, hardTTL time.Duration, refresh RefreshFunc) {
+	hardTTL = max(hardTTL, softTTL)
+
+	c.set(key, c.newRefreshEntry(data, &refresher{
+		fn:      refresh,
+		softTTL: softTTL,
+		hardTTL: hardTTL,
+	}))
+}
+
+// newRefreshEntry creates a stale-while-revalidate entry for data.
+func (c *Cache) newRefreshEntry(data any, r *refresher) *Entry {
+	now := time.Now()
+
+	refreshAfter := r.softTTL
+	if c.refreshAhead > 0 {
+		refreshAfter = time.Duration(float64(r.softTTL) * c.refreshAhead)
+	}
+
+	return &Entry{
+		Value:     data,
+		ExpiresAt: now.Add(r.hardTTL),
+		refresher: r,
+		refreshAt: now.Add(refreshAfter),
+	}
+}
+
+// refresh reloads key in the background unless a load is already in flight
+// or a previous refresh failed less than the retry delay ago.
+func (c *Cache) refresh(key string, r *refresher) {
+	c.loadMu.Lock()
+	if _, ok := c.calls[key]; ok || time.Now().Before(r.retryAt) {
+		c.loadMu.Unlock()
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.refreshTimeout)
+	cl := &call{done: make(chan struct{}), cancel: cancel}
+	c.calls[key] = cl
+	c.loadMu.Unlock()
+
+	loader := func(ctx context.Context) (any, error) {
+		return r.fn(ctx, key)
+	}
+
+	go func() {
+		c.load(ctx, key, loader, cl, func(val any, err error) *Entry {
+			if err != nil {
+				c.refreshFailedLocked(r)
+
+				return nil
+			}
+			r.failures = 0
+			r.retryAt = time.Time{}
+
+			return c.newRefreshEntry(val, r)
+		})
+
+		if cl.err != nil && c.onRefreshError != nil {
+			c.onRefreshError(key, cl.err)
+		}
+	}()
+}
+
+// refreshFailedLocked records a failed refresh of r, delaying the next one.
+// Must be called with c.loadMu held.
+func (c *Cache) refreshFailedLocked(r *refresher) {
+	limit := max(r.softTTL, c.refreshRetryDelay)
+
+	delay := c.refreshRetryDelay
+	for range r.failures {
+		if delay >= limit {
+			break
+		}
+		delay *= 2
+	}
+	delay = min(delay, limit)
+
+	r.failures++
+	r.retryAt = time.Now().Add(delay)
+}
+
+// SetWithRefresh stores a value in stale-while-revalidate mode.
+// See Cache.SetWithRefresh.
+func (t *Typed[K, V]) SetWithRefresh(key K, value V, softTTL, hardTTL time.Duration, refresh func(ctx context.Context, key K) (V, error)) {
+	t.cache.SetWithRefresh(typedKey(key), value, softTTL, hardTTL, func(ctx context.Context, _ string) (any, error) {
+		return refresh(ctx, key)
+	})
+}
@@ -0,0 +1,320 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it returns true or the timeout elapses.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func TestCache_SetWithRefreshServesStale(t *testing.T) {
+	c := New()
+
+	var calls atomic.Int32
+	refresh := func(_ context.Context, key string) (any, error) {
+		calls.Add(1)
+
+		return key + "-refreshed", nil
+	}
+
+	c.SetWithRefresh("meta", "original", 50*time.Millisecond, time.Minute, refresh)
+
+	// Fresh: no refresh
+	if v, ok := c.Get("meta"); !ok || v != "original" {
+		t.Fatalf("expected original value, got %v (ok=%v)", v, ok)
+	}
+	if calls.Load() != 0 {
+		t.Fatal("expected no refresh while fresh")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// Stale: served immediately, refreshed in background
+	if v, ok := c.Get("meta"); !ok || v != "original" {
+		t.Fatalf("expected stale value, got %v (ok=%v)", v, ok)
+	}
+
+	// The refreshed entry is fresh again, so count refreshes as soon as it
+	// shows up rather than after it may have gone stale a second time
+	var refreshes int32
+	waitFor(t, time.Second, func() bool {
+		v, _ := c.Get("meta")
+		refreshes = calls.Load()
+
+		return v == "meta-refreshed"
+	})
+
+	if refreshes != 1 {
+		t.Fatalf("expected 1 refresh, got %d", refreshes)
+	}
+}
+
+func TestCache_SetWithRefreshHardExpiry(t *testing.T) {
+	c := New()
+
+	c.SetWithRefresh("meta", "original", 5*time.Millisecond, 10*time.Millisecond,
+		func(_ context.Context, _ string) (any, error) {
+			return "refreshed", nil
+		})
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("meta"); ok {
+		t.Fatal("expected miss after hard expiry")
+	}
+}
+
+func TestCache_SetWithRefreshCoalesces(t *testing.T) {
+	c := New()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	refresh := func(_ context.Context, _ string) (any, error) {
+		calls.Add(1)
+		<-release
+
+		return "refreshed", nil
+	}
+
+	c.SetWithRefresh("meta", "original", time.Millisecond, time.Minute, refresh)
+	time.Sleep(5 * time.Millisecond)
+
+	for range 10 {
+		c.Get("meta")
+	}
+	close(release)
+
+	waitFor(t, time.Second, func() bool {
+		v, _ := c.Get("meta")
+
+		return v == "refreshed"
+	})
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected concurrent stale reads to share one refresh, got %d", calls.Load())
+	}
+}
+
+func TestCache_RefreshErrorHook(t *testing.T) {
+	refreshErr := errors.New("provider down")
+	hookErr := make(chan error, 1)
+
+	c := New(WithRefreshErrorHook(func(key string, err error) {
+		if key != "meta" {
+			t.Errorf("expected key meta, got %s", key)
+		}
+		hookErr <- err
+	}))
+
+	c.SetWithRefresh("meta", "original", time.Millisecond, time.Minute,
+		func(_ context.Context, _ string) (any, error) {
+			return nil, refreshErr
+		})
+	time.Sleep(5 * time.Millisecond)
+
+	c.Get("meta")
+
+	select {
+	case err := <-hookErr:
+		if !errors.Is(err, refreshErr) {
+			t.Fatalf("expected refreshErr, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("refresh error hook was not called")
+	}
+
+	// The stale value is still served
+	if v, ok := c.Get("meta"); !ok || v != "original" {
+		t.Fatalf("expected stale value after failed refresh, got %v (ok=%v)", v, ok)
+	}
+}
+
+func TestCache_RefreshAhead(t *testing.T) {
+	c := New(WithRefreshAhead(0.5))
+
+	var calls atomic.Int32
+	c.SetWithRefresh("meta", "original", 40*time.Millisecond, time.Minute,
+		func(_ context.Context, _ string) (any, error) {
+			calls.Add(1)
+
+			return "refreshed", nil
+		})
+
+	// Past half of the soft TTL but still fresh
+	time.Sleep(25 * time.Millisecond)
+	c.Get("meta")
+
+	waitFor(t, time.Second, func() bool {
+		v, _ := c.Get("meta")
+
+		return v == "refreshed"
+	})
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 refresh-ahead, got %d", calls.Load())
+	}
+}
+
+func TestCache_RefreshTimeout(t *testing.T) {
+	hookErr := make(chan error, 1)
+	c := New(
+		WithRefreshTimeout(10*time.Millisecond),
+		WithRefreshErrorHook(func(_ string, err error) { hookErr <- err }),
+	)
+
+	c.SetWithRefresh("meta", "original", time.Millisecond, time.Minute,
+		func(ctx context.Context, _ string) (any, error) {
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		})
+	time.Sleep(5 * time.Millisecond)
+
+	c.Get("meta")
+
+	select {
+	case err := <-hookErr:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("refresh did not time out")
+	}
+}
+
+func TestTyped_SetWithRefresh(t *testing.T) {
+	c := NewTyped[int, string](DefaultMetadataTTL)
+
+	c.SetWithRefresh(1, "original", time.Millisecond, time.Minute,
+		func(_ context.Context, key int) (string, error) {
+			if key != 1 {
+				t.Errorf("expected key 1, got %d", key)
+			}
+
+			return "refreshed", nil
+		})
+	time.Sleep(5 * time.Millisecond)
+
+	c.Get(1)
+
+	waitFor(t, time.Second, func() bool {
+		v, _ := c.Get(1)
+
+		return v == "refreshed"
+	})
+}
+
+func TestCache_RefreshFailureBacksOff(t *testing.T) {
+	hookErr := make(chan error, 10)
+	c := New(
+		WithRefreshRetryDelay(50*time.Millisecond),
+		WithRefreshErrorHook(func(_ string, err error) { hookErr <- err }),
+	)
+
+	var calls atomic.Int32
+	c.SetWithRefresh("meta", "original", time.Millisecond, time.Minute,
+		func(_ context.Context, _ string) (any, error) {
+			calls.Add(1)
+
+			return nil, errors.New("provider down")
+		})
+	time.Sleep(5 * time.Millisecond)
+
+	c.Get("meta")
+	select {
+	case <-hookErr:
+	case <-time.After(time.Second):
+		t.Fatal("refresh did not fail")
+	}
+
+	// Reads within the retry delay serve the stale value without refreshing
+	for range 20 {
+		if v, ok := c.Get("meta"); !ok || v != "original" {
+			t.Fatalf("expected stale value, got %v (ok=%v)", v, ok)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 refresh within the retry delay, got %d", n)
+	}
+
+	// Once the delay has passed, a read retries
+	time.Sleep(60 * time.Millisecond)
+	c.Get("meta")
+	waitFor(t, time.Second, func() bool { return calls.Load() == 2 })
+
+	select {
+	case <-hookErr:
+	case <-time.After(time.Second):
+		t.Fatal("second refresh did not fail")
+	}
+}
+
+func TestCache_RefreshRetryDelayDoubles(t *testing.T) {
+	c := New(WithRefreshRetryDelay(time.Second))
+	r := &refresher{softTTL: 5 * time.Second}
+
+	var delays []time.Duration
+	for range 5 {
+		start := time.Now()
+		c.refreshFailedLocked(r)
+		delays = append(delays, r.retryAt.Sub(start).Round(time.Second))
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	if !slices.Equal(delays, want) {
+		t.Fatalf("expected delays %v, got %v", want, delays)
+	}
+}
+
+func TestCache_RefreshDroppedAfterDelete(t *testing.T) {
+	c := New()
+
+	var calls atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	refresh := func(_ context.Context, _ string) (any, error) {
+		defer close(finished)
+		calls.Add(1)
+		started <- struct{}{}
+		<-release
+
+		return "v2", nil
+	}
+
+	c.SetWithRefresh("k", "v1", time.Millisecond, time.Minute, refresh)
+	time.Sleep(5 * time.Millisecond)
+
+	// Stale read starts a refresh, then the key is deleted while it runs
+	c.Get("k")
+	<-started
+	c.Delete("k")
+	close(release)
+	<-finished
+
+	// Give the refresh time to (not) store its result
+	time.Sleep(20 * time.Millisecond)
+
+	if v, ok := c.Get("k"); ok {
+		t.Fatalf("expected deleted key to stay deleted, got %v", v)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected no refresh after delete, got %d", n)
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Scheduler is a running cleanup goroutine started by StartCleanup.
+// All methods are safe for concurrent use and may be called more than once.
+type Scheduler struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartCleanup runs periodic cleanup of expired entries until ctx is done
+// or the returned Scheduler is stopped.
+//
+// Usage:
+//
+//	sched := c.StartCleanup(ctx, time.Minute)
+//	defer sched.Shutdown()
+func (c *Cache) StartCleanup(ctx context.Context, interval time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Scheduler{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.Cleanup()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+// Stop signals the scheduler to stop without waiting for it to exit.
+func (s *Scheduler) Stop() {
+	s.cancel()
+}
+
+// Done returns a channel that is closed once the scheduler goroutine has exited.
+func (s *Scheduler) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait blocks until the scheduler goroutine has exited.
+func (s *Scheduler) Wait() {
+	<-s.done
+}
+
+// Shutdown stops the scheduler and waits for it to exit.
+func (s *Scheduler) Shutdown() {
+	s.Stop()
+	s.Wait()
+}
+
+// StartCleanup runs periodic cleanup of expired entries until ctx is done.
+// See Cache.StartCleanup.
+func (t *Typed[K, V]) StartCleanup(ctx context.Context, interval time.Duration) *Scheduler {
+	return t.cache.StartCleanup(ctx, interval)
+}
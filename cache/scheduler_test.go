@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCache_StartCleanup(t *testing.T) {
+	c := New()
+
+	sched := c.StartCleanup(context.Background(), 10*time.Millisecond)
+	defer sched.Shutdown()
+
+	c.Set("key1", "value1", 5*time.Millisecond)
+
+	waitFor(t, time.Second, func() bool { return c.Size() == 0 })
+}
+
+func TestScheduler_StopsOnContextCancel(t *testing.T) {
+	c := New()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sched := c.StartCleanup(ctx, time.Hour)
+	cancel()
+
+	select {
+	case <-sched.Done():
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop on context cancellation")
+	}
+}
+
+func TestScheduler_StopIsIdempotent(t *testing.T) {
+	c := New()
+	sched := c.StartCleanup(context.Background(), time.Hour)
+
+	sched.Stop()
+	sched.Stop()
+	sched.Wait()
+	sched.Shutdown()
+
+	select {
+	case <-sched.Done():
+	default:
+		t.Fatal("expected Done to be closed after Wait")
+	}
+}
+
+func TestScheduler_NoCleanupAfterStop(t *testing.T) {
+	c := New()
+	sched := c.StartCleanup(context.Background(), 5*time.Millisecond)
+	sched.Shutdown()
+	before := c.Stats().Cleanups
+
+	c.Set("key1", "value1", time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	if after := c.Stats().Cleanups; after != before {
+		t.Fatalf("expected no cleanup after shutdown, got %d more", after-before)
+	}
+	if c.Size() != 1 {
+		t.Fatalf("expected expired entry to remain, got size %d", c.Size())
+	}
+}
+
+func TestTyped_StartCleanup(t *testing.T) {
+	c := NewTyped[string, int](time.Millisecond)
+
+	sched := c.StartCleanup(context.Background(), 5*time.Millisecond)
+	defer sched.Shutdown()
+
+	c.Set("a", 1)
+
+	waitFor(t, time.Second, func() bool { return c.Size() == 0 })
+}
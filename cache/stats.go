@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/valksor/go-toolkit/eventbus"
+)
+
+// Event types published when a bus is configured with WithEventBus.
+// Event data contains "key" and "prefix" (the part of the key before the
+// first ':', or the whole key if it has none).
+const (
+	EventHit     eventbus.Type = "cache.hit"
+	EventMiss    eventbus.Type = "cache.miss"
+	EventExpired eventbus.Type = "cache.expired"
+	EventSet     eventbus.Type = "cache.set"
+	EventDelete  eventbus.Type = "cache.delete"
+	EventEvict   eventbus.Type = "cache.evict"
+	EventCleanup eventbus.Type = "cache.cleanup"
+)
+
+// counters holds the running totals behind Stats.
+type counters struct {
+	hits         atomic.Uint64
+	misses       atomic.Uint64
+	expiredReads atomic.Uint64
+	sets         atomic.Uint64
+	deletes      atomic.Uint64
+	evictions    atomic.Uint64
+	cleanups     atomic.Uint64
+	cleanedUp    atomic.Uint64
+}
+
+// Stats is a point-in-time snapshot of cache activity.
+type Stats struct {
+	// Hits counts reads that found a live entry, including cached
+	// negative results served by GetOrLoad.
+	Hits uint64
+	// Misses counts reads that found no live entry, including expired reads.
+	Misses uint64
+	// ExpiredReads counts reads that found an expired entry.
+	ExpiredReads uint64
+	// Sets counts stored entries.
+	Sets uint64
+	// Deletes counts entries removed by Delete.
+	Deletes uint64
+	// Evictions counts entries evicted to stay within bounds.
+	Evictions uint64
+	// Cleanups counts Cleanup runs.
+	Cleanups uint64
+	// CleanedUp counts expired entries removed by Cleanup.
+	CleanedUp uint64
+	// Size is the current number of entries.
+	Size int
+	// Bytes is the current estimated size of cached values.
+	Bytes int64
+}
+
+// HitRatio returns the fraction of reads that were hits, or 0 if there were no reads.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// WithEventBus publishes cache activity onto bus using the Event* types.
+// Events are published synchronously and only when the event type has
+// subscribers, so handlers should be fast.
+func WithEventBus(bus *eventbus.Bus) Option {
+	return func(o *options) {
+		o.bus = bus
+	}
+}
+
+// Stats returns a snapshot of cache counters.
+// Reads made while the cache is disabled are not counted.
+func (c *Cache) Stats() Stats {
+	c.syncShared()
+
+	c.mu.RLock()
+	size := c.backend.Len()
+	bytes := c.bytes
+	c.mu.RUnlock()
+
+	return Stats{
+		Hits:         c.stats.hits.Load(),
+		Misses:       c.stats.misses.Load(),
+		ExpiredReads: c.stats.expiredReads.Load(),
+		Sets:         c.stats.sets.Load(),
+		Deletes:      c.stats.deletes.Load(),
+		Evictions:    c.stats.evictions.Load(),
+		Cleanups:     c.stats.cleanups.Load(),
+		CleanedUp:    c.stats.cleanedUp.Load(),
+		Size:         size,
+		Bytes:        bytes,
+	}
+}
+
+// ResetStats zeroes all counters. Size and Bytes are not affected.
+func (c *Cache) ResetStats() {
+	c.stats.hits.Store(0)
+	c.stats.misses.Store(0)
+	c.stats.expiredReads.Store(0)
+	c.stats.sets.Store(0)
+	c.stats.deletes.Store(0)
+	c.stats.evictions.Store(0)
+	c.stats.cleanups.Store(0)
+	c.stats.cleanedUp.Store(0)
+}
+
+// KeyPrefix returns the part of key before the first ':', or key itself.
+// Example: KeyPrefix("issue:123") returns "issue".
+func KeyPrefix(key string) string {
+	prefix, _, _ := strings.Cut(key, KeySeparator)
+
+	return prefix
+}
+
+// emit publishes a cache event for key. Must be called without c.mu held,
+// since handlers may call back into the cache.
+func (c *Cache) emit(eventType eventbus.Type, key string) {
+	if c.bus == nil || !c.bus.HasSubscribers(eventType) {
+		return
+	}
+
+	c.bus.PublishRaw(eventbus.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Data: map[string]any{
+			"key":    key,
+			"prefix": KeyPrefix(key),
+		},
+	})
+}
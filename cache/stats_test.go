@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-toolkit/eventbus"
+)
+
+func TestCache_Stats(t *testing.T) {
+	c := New(WithMaxEntries(2))
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, 10*time.Millisecond)
+	c.Get("a")
+	c.Get("missing")
+
+	time.Sleep(20 * time.Millisecond)
+	c.Get("b")
+
+	c.Set("c", 3, time.Minute) // evicts b
+	c.Set("d", 4, time.Minute) // evicts a
+	c.Delete("c")
+	c.Delete("never-set")
+	c.Cleanup()
+
+	s := c.Stats()
+
+	if s.Hits != 1 {
+		t.Errorf("expected 1 hit, got %d", s.Hits)
+	}
+	if s.Misses != 2 {
+		t.Errorf("expected 2 misses, got %d", s.Misses)
+	}
+	if s.ExpiredReads != 1 {
+		t.Errorf("expected 1 expired read, got %d", s.ExpiredReads)
+	}
+	if s.Sets != 4 {
+		t.Errorf("expected 4 sets, got %d", s.Sets)
+	}
+	if s.Deletes != 1 {
+		t.Errorf("expected 1 delete, got %d", s.Deletes)
+	}
+	if s.Evictions != 2 {
+		t.Errorf("expected 2 evictions, got %d", s.Evictions)
+	}
+	if s.Cleanups != 1 {
+		t.Errorf("expected 1 cleanup run, got %d", s.Cleanups)
+	}
+	if s.Size != 1 {
+		t.Errorf("expected size 1, got %d", s.Size)
+	}
+	if ratio := s.HitRatio(); ratio < 0.33 || ratio > 0.34 {
+		t.Errorf("expected hit ratio 1/3, got %f", ratio)
+	}
+
+	c.ResetStats()
+	if s := c.Stats(); s.Hits != 0 || s.Sets != 0 {
+		t.Errorf("expected counters reset, got %+v", s)
+	}
+}
+
+func TestCache_StatsGetOrLoad(t *testing.T) {
+	c := New()
+	loader := func(_ context.Context) (any, error) { return 1, nil }
+
+	_, _ = c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+	_, _ = c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 1 || s.Sets != 1 {
+		t.Fatalf("expected 1 hit, 1 miss, 1 set, got %+v", s)
+	}
+}
+
+func TestStats_HitRatioNoReads(t *testing.T) {
+	if r := (Stats{}).HitRatio(); r != 0 {
+		t.Fatalf("expected 0, got %f", r)
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"issue:123", "issue"},
+		{"comments:123:page:2", "comments"},
+		{"plain", "plain"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := KeyPrefix(tt.key); got != tt.want {
+			t.Errorf("KeyPrefix(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCache_EventBus(t *testing.T) {
+	bus := eventbus.NewBus()
+	defer bus.Shutdown()
+
+	var mu sync.Mutex
+	counts := make(map[eventbus.Type]int)
+	prefixes := make(map[string]int)
+
+	bus.SubscribeAll(func(e eventbus.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		counts[e.Type]++
+		if p, ok := e.Data["prefix"].(string); ok {
+			prefixes[p]++
+		}
+	})
+
+	c := New(WithEventBus(bus), WithMaxEntries(1))
+
+	c.Set("issue:1", 1, time.Minute)
+	c.Get("issue:1")
+	c.Get("issue:2")
+	c.Set("comments:1", 1, time.Minute) // evicts issue:1
+	c.Delete("comments:1")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := map[eventbus.Type]int{
+		EventSet:    2,
+		EventHit:    1,
+		EventMiss:   1,
+		EventEvict:  1,
+		EventDelete: 1,
+	}
+	for typ, n := range expected {
+		if counts[typ] != n {
+			t.Errorf("expected %d %s events, got %d", n, typ, counts[typ])
+		}
+	}
+
+	if prefixes["issue"] != 4 || prefixes["comments"] != 2 {
+		t.Errorf("unexpected per-prefix counts %v", prefixes)
+	}
+}
+
+func TestCache_EventHandlerMayUseCache(t *testing.T) {
+	bus := eventbus.NewBus()
+	defer bus.Shutdown()
+
+	c := New(WithEventBus(bus))
+
+	// Handlers run outside the cache lock, so re-entrant calls must not deadlock
+	bus.Subscribe(EventSet, func(e eventbus.Event) {
+		key, _ := e.Data["key"].(string)
+		c.Get(key)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		c.Set("key", 1, time.Minute)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deadlock publishing cache event")
+	}
+}
+
+func TestCache_EventHandlerMayLoad(t *testing.T) {
+	bus := eventbus.NewBus()
+	defer bus.Shutdown()
+
+	c := New(WithEventBus(bus))
+
+	// Events for loaded values are published after loadMu is released
+	bus.Subscribe(EventSet, func(e eventbus.Event) {
+		if e.Data["key"] != "a" {
+			return
+		}
+		_, _ = c.GetOrLoad(context.Background(), "b", time.Minute, func(context.Context) (any, error) {
+			return 2, nil
+		})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = c.GetOrLoad(context.Background(), "a", time.Minute, func(context.Context) (any, error) {
+			return 1, nil
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deadlock publishing cache event from GetOrLoad")
+	}
+
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Fatalf("expected b to be loaded by the handler, got %v (ok=%v)", v, ok)
+	}
+}
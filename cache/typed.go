@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Typed is a type-safe cache keyed by K and storing values of type V.
+//
+// It wraps a Cache, so TTL expiration, enable/disable, bounds and cleanup
+// behave exactly as they do for the untyped cache.
+//
+// Usage:
+//
+//	issues := cache.NewTyped[string, *Issue](cache.DefaultIssueTTL)
+//	issues.Set("123", issue)
+//	if issue, ok := issues.Get("123"); ok {
+//	    // issue is *Issue, no type assertion needed
+//	}
+type Typed[K comparable, V any] struct {
+	cache *Cache
+	ttl   time.Duration
+}
+
+// NewTyped creates a typed cache whose Set uses defaultTTL.
+// Options are the same as for New.
+func NewTyped[K comparable, V any](defaultTTL time.Duration, opts ...Option) *Typed[K, V] {
+	return &Typed[K, V]{
+		cache: New(opts...),
+		ttl:   defaultTTL,
+	}
+}
+
+// typedKey converts a typed key into the string key used by the underlying cache.
+// String keys are used as-is; other keys include their type to avoid collisions
+// between values that format identically (e.g. int(1) and int64(1) in an any key).
+func typedKey[K comparable](key K) string {
+	if s, ok := any(key).(string); ok {
+		return s
+	}
+
+	return fmt.Sprintf("%T:%#v", key, key)
+}
+
+// Get retrieves a value by key.
+// Returns the zero value and false if not found or expired.
+//
+// WARNING: As with Cache.Get, the returned value is not copied. Do NOT modify
+// reference types (pointers, slices, maps) returned from the cache.
+func (t *Typed[K, V]) Get(key K) (V, bool) {
+	val, ok := t.cache.Get(typedKey(key))
+	if !ok {
+		var zero V
+
+		return zero, false
+	}
+
+	return asTyped[V](val)
+}
+
+// asTyped converts a cached value to V. Values loaded by a persistent backend
+// are json.RawMessage and are decoded into V.
+func asTyped[V any](val any) (V, bool) {
+	if v, ok := val.(V); ok {
+		return v, true
+	}
+
+	var v V
+	raw, ok := val.(json.RawMessage)
+	if !ok {
+		return v, false
+	}
+
+	if err := json.Unmarshal(raw, &v); err != nil {
+		var zero V
+
+		return zero, false
+	}
+
+	return v, true
+}
+
+// Set stores a value with the cache's default TTL.
+func (t *Typed[K, V]) Set(key K, value V) {
+	t.cache.Set(typedKey(key), value, t.ttl)
+}
+
+// SetWithTTL stores a value with an explicit TTL.
+func (t *Typed[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	t.cache.Set(typedKey(key), value, ttl)
+}
+
+// Delete removes a value from the cache.
+func (t *Typed[K, V]) Delete(key K) {
+	t.cache.Delete(typedKey(key))
+}
+
+// Clear removes all entries from the cache.
+func (t *Typed[K, V]) Clear() {
+	t.cache.Clear()
+}
+
+// Size returns the number of entries in the cache.
+func (t *Typed[K, V]) Size() int {
+	return t.cache.Size()
+}
+
+// Cleanup removes all expired entries from the cache.
+func (t *Typed[K, V]) Cleanup() {
+	t.cache.Cleanup()
+}
+
+// Enable enables the cache.
+func (t *Typed[K, V]) Enable() {
+	t.cache.Enable()
+}
+
+// Disable disables the cache (all Get operations will return cache miss).
+func (t *Typed[K, V]) Disable() {
+	t.cache.Disable()
+}
+
+// Enabled returns true if caching is enabled.
+func (t *Typed[K, V]) Enabled() bool {
+	return t.cache.Enabled()
+}
+
+// TTL returns the default TTL used by Set.
+func (t *Typed[K, V]) TTL() time.Duration {
+	return t.ttl
+}
+
+// StartCleanupScheduler runs periodic cleanup of expired entries.
+// See Cache.StartCleanupScheduler.
+func (t *Typed[K, V]) StartCleanupScheduler(interval time.Duration) chan struct{} {
+	return t.cache.StartCleanupScheduler(interval)
+}
+
+// Cache returns the underlying untyped cache, e.g. to read Evictions.
+// Values stored through it that are not of type V are reported as misses by Get.
+func (t *Typed[K, V]) Cache() *Cache {
+	return t.cache
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type testIssue struct {
+	ID    int
+	Title string
+}
+
+func TestTyped_SetAndGet(t *testing.T) {
+	c := NewTyped[string, *testIssue](DefaultIssueTTL)
+
+	c.Set("123", &testIssue{ID: 123, Title: "bug"})
+
+	issue, ok := c.Get("123")
+	if !ok {
+		t.Fatal("expected to find 123")
+	}
+	if issue.Title != "bug" {
+		t.Fatalf("expected title 'bug', got %q", issue.Title)
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("expected cache miss")
+	}
+
+	if c.TTL() != DefaultIssueTTL {
+		t.Fatalf("expected TTL %v, got %v", DefaultIssueTTL, c.TTL())
+	}
+}
+
+func TestTyped_NonStringKeys(t *testing.T) {
+	type key struct {
+		Provider string
+		ID       int
+	}
+
+	c := NewTyped[key, string](time.Minute)
+
+	c.Set(key{"github", 1}, "one")
+	c.Set(key{"jira", 1}, "other")
+
+	val, ok := c.Get(key{"github", 1})
+	if !ok || val != "one" {
+		t.Fatalf("expected 'one', got %q (ok=%v)", val, ok)
+	}
+
+	c.Delete(key{"github", 1})
+	if _, ok := c.Get(key{"github", 1}); ok {
+		t.Fatal("expected cache miss after delete")
+	}
+	if c.Size() != 1 {
+		t.Fatalf("expected size 1, got %d", c.Size())
+	}
+}
+
+func TestTyped_AnyKeysDoNotCollide(t *testing.T) {
+	c := NewTyped[any, string](time.Minute)
+
+	c.Set(1, "int")
+	c.Set(int64(1), "int64")
+
+	val, _ := c.Get(1)
+	if val != "int" {
+		t.Fatalf("expected 'int', got %q", val)
+	}
+	val, _ = c.Get(int64(1))
+	if val != "int64" {
+		t.Fatalf("expected 'int64', got %q", val)
+	}
+}
+
+func TestTyped_ExpirationAndDisable(t *testing.T) {
+	c := NewTyped[int, int](time.Minute)
+
+	c.SetWithTTL(1, 100, 10*time.Millisecond)
+	c.Set(2, 200)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get(1); ok {
+		t.Fatal("expected key 1 to be expired")
+	}
+
+	c.Cleanup()
+	if c.Size() != 1 {
+		t.Fatalf("expected size 1 after cleanup, got %d", c.Size())
+	}
+
+	c.Disable()
+	if c.Enabled() {
+		t.Fatal("expected cache to be disabled")
+	}
+	if _, ok := c.Get(2); ok {
+		t.Fatal("expected cache miss when disabled")
+	}
+
+	c.Enable()
+	if v, ok := c.Get(2); !ok || v != 200 {
+		t.Fatalf("expected 200 after re-enable, got %d (ok=%v)", v, ok)
+	}
+}
+
+func TestTyped_WrongTypeIsMiss(t *testing.T) {
+	c := NewTyped[string, int](time.Minute)
+
+	c.Cache().Set("key", "not an int", time.Minute)
+
+	v, ok := c.Get("key")
+	if ok {
+		t.Fatal("expected miss for value of wrong type")
+	}
+	if v != 0 {
+		t.Fatalf("expected zero value, got %d", v)
+	}
+}
+
+func TestTyped_Bounded(t *testing.T) {
+	c := NewTyped[string, int](time.Minute, WithMaxEntries(1))
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	if c.Size() != 1 {
+		t.Fatalf("expected size 1, got %d", c.Size())
+	}
+	if c.Cache().Evictions() != 1 {
+		t.Fatalf("expected 1 eviction, got %d", c.Cache().Evictions())
+	}
+}
@@ -0,0 +1,416 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-toolkit/log"
+)
+
+const (
+	// DefaultBridgeBuffer is the number of events buffered per bridge
+	// connection before the oldest are dropped.
+	DefaultBridgeBuffer = 1024
+	// DefaultReconnectMin is the initial delay before a BridgeClient reconnects.
+	DefaultReconnectMin = 100 * time.Millisecond
+	// DefaultReconnectMax caps the delay between BridgeClient reconnects.
+	DefaultReconnectMax = 10 * time.Second
+
+	// bridgeHandshakeTimeout bounds how long a new connection may take to
+	// send its hello.
+	bridgeHandshakeTimeout = 5 * time.Second
+	// bridgeWriteTimeout bounds a single event write to a client.
+	bridgeWriteTimeout = 5 * time.Second
+)
+
+// wireEvent is the JSON line sent over a bridge connection.
+type wireEvent struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Data      map[string]any `json:"data,omitempty"`
+	Type      Type           `json:"type"`
+}
+
+// bridgeHello is the first line a client sends after connecting.
+type bridgeHello struct {
+	// Types lists the patterns the client wants; empty means all events.
+	Types []string `json:"types,omitempty"`
+}
+
+// BridgeOption configures a Bridge.
+type BridgeOption func(*bridgeOptions)
+
+type bridgeOptions struct {
+	buffer int
+}
+
+// WithBridgeBuffer sets how many events are buffered per connection.
+// When a client falls behind, the oldest buffered events are dropped so the
+// bus is never blocked. Defaults to DefaultBridgeBuffer.
+func WithBridgeBuffer(n int) BridgeOption {
+	return func(o *bridgeOptions) {
+		if n > 0 {
+			o.buffer = n
+		}
+	}
+}
+
+// Bridge forwards events published on a Bus to other processes over a Unix
+// domain socket. Each event is sent as one JSON line holding its type, data
+// and timestamp. Typed payloads travel through Data; see PayloadAs.
+//
+// The bridge is one-way: clients only receive events. Do not run a Bridge
+// and a BridgeClient for the same socket on one Bus, or events will loop.
+type Bridge struct {
+	bus      *Bus
+	listener net.Listener
+	path     string
+	buffer   int
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// ListenBridge listens on the Unix socket at path and forwards events from
+// bus to every connected client. A stale socket file left by a previous
+// process is removed.
+func ListenBridge(bus *Bus, path string, opts ...BridgeOption) (*Bridge, error) {
+	o := &bridgeOptions{buffer: DefaultBridgeBuffer}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on bridge socket: %w", err)
+	}
+
+	b := &Bridge{
+		bus:      bus,
+		listener: listener,
+		path:     path,
+		buffer:   o.buffer,
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	b.wg.Go(b.accept)
+
+	return b, nil
+}
+
+// Path returns the socket path.
+func (b *Bridge) Path() string {
+	return b.path
+}
+
+// Clients returns the number of connected clients.
+func (b *Bridge) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.conns)
+}
+
+// Close stops accepting clients, disconnects existing ones and removes the
+// socket file.
+func (b *Bridge) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+
+		return nil
+	}
+	b.closed = true
+
+	err := b.listener.Close()
+	for conn := range b.conns {
+		_ = conn.Close()
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+
+	if rmErr := os.Remove(b.path); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+		err = rmErr
+	}
+
+	return err
+}
+
+func (b *Bridge) accept() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Warn("eventbus bridge accept failed", log.Err(err))
+			}
+
+			return
+		}
+
+		b.wg.Go(func() { b.serve(conn) })
+	}
+}
+
+// serve reads the client hello, then forwards matching events until the
+// connection fails or the bridge is closed.
+func (b *Bridge) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	if !b.track(conn) {
+		return
+	}
+	defer b.untrack(conn)
+
+	hello, err := readHello(conn)
+	if err != nil {
+		log.Warn("eventbus bridge handshake failed", log.Err(err))
+
+		return
+	}
+
+	filter := newPatternTrie()
+	for _, pattern := range hello.Types {
+		filter.add(pattern, Subscription{ID: pattern})
+	}
+
+	// lost is closed when the client disconnects or a write fails
+	lost := make(chan struct{})
+	var lostOnce sync.Once
+	markLost := func() { lostOnce.Do(func() { close(lost) }) }
+
+	enc := json.NewEncoder(conn)
+	id := b.bus.SubscribeAllE(func(e Event) error {
+		if len(hello.Types) > 0 && !filter.matches(e.Type) {
+			return nil
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(bridgeWriteTimeout))
+		if err := enc.Encode(wireEvent{Type: e.Type, Data: e.Data, Timestamp: e.Timestamp}); err != nil {
+			markLost()
+		}
+
+		return nil
+	}, WithQueue(b.buffer, OverflowDropOldest))
+	defer b.bus.Unsubscribe(id)
+
+	// Clients send nothing after the hello; a read returning means they left
+	go func() {
+		_, _ = conn.Read(make([]byte, 1))
+		markLost()
+	}()
+
+	<-lost
+}
+
+func (b *Bridge) track(conn net.Conn) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return false
+	}
+	b.conns[conn] = struct{}{}
+
+	return true
+}
+
+func (b *Bridge) untrack(conn net.Conn) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.conns, conn)
+}
+
+func readHello(conn net.Conn) (bridgeHello, error) {
+	var hello bridgeHello
+
+	_ = conn.SetReadDeadline(time.Now().Add(bridgeHandshakeTimeout))
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return hello, fmt.Errorf("reading hello: %w", err)
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	if err := json.Unmarshal(line, &hello); err != nil {
+		return hello, fmt.Errorf("decoding hello: %w", err)
+	}
+
+	return hello, nil
+}
+
+// removeStaleSocket removes a socket file at path that no process is
+// listening on.
+func removeStaleSocket(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+
+		return fmt.Errorf("bridge socket %s is already in use", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing stale bridge socket: %w", err)
+	}
+
+	return nil
+}
+
+// BridgeClientOption configures a BridgeClient.
+type BridgeClientOption func(*bridgeClientOptions)
+
+type bridgeClientOptions struct {
+	types        []string
+	reconnectMin time.Duration
+	reconnectMax time.Duration
+}
+
+// WithTypes limits the events received to types matching any of the given
+// patterns (see SubscribePattern). Filtering happens on the bridge side, so
+// other events never cross the socket. By default all events are received.
+func WithTypes(patterns ...string) BridgeClientOption {
+	return func(o *bridgeClientOptions) {
+		o.types = append(o.types, patterns...)
+	}
+}
+
+// WithReconnectDelay sets the delay before reconnecting after the connection
+// fails. The delay doubles after each failed attempt, from minDelay up to
+// maxDelay. Defaults to DefaultReconnectMin and DefaultReconnectMax.
+func WithReconnectDelay(minDelay, maxDelay time.Duration) BridgeClientOption {
+	return func(o *bridgeClientOptions) {
+		if minDelay > 0 {
+			o.reconnectMin = minDelay
+		}
+		if maxDelay >= o.reconnectMin {
+			o.reconnectMax = maxDelay
+		}
+	}
+}
+
+// BridgeClient connects to a Bridge and publishes the received events on a
+// local Bus.
+type BridgeClient struct {
+	bus          *Bus
+	path         string
+	types        []string
+	reconnectMin time.Duration
+	reconnectMax time.Duration
+
+	mu        sync.Mutex
+	connected bool
+}
+
+// NewBridgeClient creates a client that injects events from the bridge at
+// path into bus. Call Run to connect.
+func NewBridgeClient(bus *Bus, path string, opts ...BridgeClientOption) *BridgeClient {
+	o := &bridgeClientOptions{
+		reconnectMin: DefaultReconnectMin,
+		reconnectMax: DefaultReconnectMax,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &BridgeClient{
+		bus:          bus,
+		path:         path,
+		types:        o.types,
+		reconnectMin: o.reconnectMin,
+		reconnectMax: max(o.reconnectMax, o.reconnectMin),
+	}
+}
+
+// Connected reports whether the client is currently connected.
+func (c *BridgeClient) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.connected
+}
+
+// Run connects to the bridge and publishes received events on the local bus
+// until ctx is cancelled, reconnecting whenever the connection fails.
+// Returns ctx.Err().
+func (c *BridgeClient) Run(ctx context.Context) error {
+	delay := c.reconnectMin
+
+	for {
+		received, err := c.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			delay = c.reconnectMin
+		}
+		log.Debug("eventbus bridge disconnected", "path", c.path, log.Err(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, c.reconnectMax)
+	}
+}
+
+// session runs one connection. It reports whether the handshake succeeded,
+// which resets the reconnect delay.
+func (c *BridgeClient) session(ctx context.Context) (bool, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Unblock the read loop when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if err := json.NewEncoder(conn).Encode(bridgeHello{Types: c.types}); err != nil {
+		return false, fmt.Errorf("sending hello: %w", err)
+	}
+
+	c.setConnected(true)
+	defer c.setConnected(false)
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJournalLine)
+	for scanner.Scan() {
+		var e wireEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			log.Warn("eventbus bridge received malformed event", log.Err(err))
+
+			continue
+		}
+		c.bus.PublishRaw(Event{Type: e.Type, Data: e.Data, Timestamp: e.Timestamp})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return true, err
+	}
+
+	return true, errors.New("bridge closed the connection")
+}
+
+func (c *BridgeClient) setConnected(v bool) {
+	c.mu.Lock()
+	c.connected = v
+	c.mu.Unlock()
+}
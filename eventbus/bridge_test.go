@@ -0,0 +1,213 @@
+package eventbus
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// socketPath returns a short socket path; Unix socket paths are limited to
+// about 100 bytes, which t.TempDir can exceed.
+func socketPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "eb")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "bus.sock")
+}
+
+// waitUntil polls cond until it returns true or the timeout elapses.
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+// recorder collects event types from a bus.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) handle(e Event) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+}
+
+func (r *recorder) types() []Type {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	types := make([]Type, len(r.events))
+	for i, e := range r.events {
+		types[i] = e.Type
+	}
+
+	return types
+}
+
+func startClient(t *testing.T, client *BridgeClient) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = client.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestBridge_ForwardsEvents(t *testing.T) {
+	path := socketPath(t)
+
+	source := NewBus()
+	defer source.Shutdown()
+
+	bridge, err := ListenBridge(source, path)
+	if err != nil {
+		t.Fatalf("ListenBridge: %v", err)
+	}
+	defer func() { _ = bridge.Close() }()
+
+	local := NewBus()
+	defer local.Shutdown()
+
+	var rec recorder
+	local.SubscribeAll(rec.handle)
+
+	startClient(t, NewBridgeClient(local, path, WithTypes("task.**")))
+	waitUntil(t, func() bool { return source.HasSubscribers("task.created") })
+
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	source.PublishRaw(Event{Type: "agent.started"})
+	PublishTyped(source, "task.created", taskCreated{ID: "t1", Title: "remote"})
+	source.PublishRaw(Event{Type: "task.step.done", Timestamp: ts})
+
+	waitUntil(t, func() bool { return len(rec.types()) == 2 })
+
+	if got := rec.types(); got[0] != "task.created" || got[1] != "task.step.done" {
+		t.Fatalf("unexpected events: %v", got)
+	}
+
+	payload, err := PayloadAs[taskCreated](rec.events[0])
+	if err != nil || payload.Title != "remote" {
+		t.Fatalf("expected payload to cross the bridge, got %+v, %v", payload, err)
+	}
+	if !rec.events[1].Timestamp.Equal(ts) {
+		t.Fatalf("expected timestamp %v, got %v", ts, rec.events[1].Timestamp)
+	}
+}
+
+func TestBridge_ClientReconnects(t *testing.T) {
+	path := socketPath(t)
+
+	source := NewBus()
+	defer source.Shutdown()
+
+	bridge, err := ListenBridge(source, path)
+	if err != nil {
+		t.Fatalf("ListenBridge: %v", err)
+	}
+
+	local := NewBus()
+	defer local.Shutdown()
+
+	var rec recorder
+	local.SubscribeAll(rec.handle)
+
+	client := NewBridgeClient(local, path, WithReconnectDelay(10*time.Millisecond, 50*time.Millisecond))
+	startClient(t, client)
+	waitUntil(t, client.Connected)
+
+	if err := bridge.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket file to be removed, got %v", err)
+	}
+	waitUntil(t, func() bool { return !client.Connected() })
+
+	bridge, err = ListenBridge(source, path)
+	if err != nil {
+		t.Fatalf("ListenBridge after restart: %v", err)
+	}
+	defer func() { _ = bridge.Close() }()
+
+	waitUntil(t, func() bool { return bridge.Clients() == 1 && source.HasSubscribers("x") })
+
+	source.PublishRaw(Event{Type: "after.restart"})
+	waitUntil(t, func() bool { return len(rec.types()) == 1 })
+}
+
+func TestBridge_ClientDisconnectUnsubscribes(t *testing.T) {
+	path := socketPath(t)
+
+	source := NewBus()
+	defer source.Shutdown()
+
+	bridge, err := ListenBridge(source, path)
+	if err != nil {
+		t.Fatalf("ListenBridge: %v", err)
+	}
+	defer func() { _ = bridge.Close() }()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if _, err := conn.Write([]byte("{}\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	waitUntil(t, func() bool { return source.HasSubscribers("any") })
+
+	_ = conn.Close()
+	waitUntil(t, func() bool { return !source.HasSubscribers("any") && bridge.Clients() == 0 })
+}
+
+func TestListenBridge_SocketInUse(t *testing.T) {
+	path := socketPath(t)
+
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	bridge, err := ListenBridge(bus, path)
+	if err != nil {
+		t.Fatalf("ListenBridge: %v", err)
+	}
+	defer func() { _ = bridge.Close() }()
+
+	if _, err := ListenBridge(bus, path); err == nil {
+		t.Fatal("expected error for socket in use")
+	}
+
+	// A leftover file with no listener is replaced
+	stale := socketPath(t)
+	if err := os.WriteFile(stale, nil, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	other, err := ListenBridge(bus, stale)
+	if err != nil {
+		t.Fatalf("expected stale socket to be replaced: %v", err)
+	}
+	_ = other.Close()
+}
@@ -2,9 +2,13 @@ package eventbus
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"sync"
+	"time"
+
+	"github.com/valksor/go-toolkit/log"
 )
 
 const (
@@ -12,6 +16,9 @@ const (
 	maxAsyncPublishes = 100
 )
 
+// ErrNoJournal is returned by Replay when the bus has no journal.
+var ErrNoJournal = errors.New("bus has no journal")
+
 // Handler processes events.
 type Handler func(Event)
 
@@ -20,6 +27,37 @@ type Subscription struct {
 	ID      string
 	Type    Type
 	Handler Handler
+	// handle runs the handler; plain handlers are wrapped to return nil.
+	handle ErrorHandler
+	// queue is set for subscriptions created with WithQueue.
+	queue *subscriberQueue
+}
+
+// Option configures a Bus.
+type Option func(*options)
+
+type options struct {
+	errorHook  func(*DeliveryError)
+	journal    *Journal
+	publishMW  []Middleware
+	deliveryMW []Middleware
+}
+
+// WithErrorHook sets a function called for every handler that returns an
+// error or panics, including during asynchronous publishes. By default
+// failures are logged at error level.
+func WithErrorHook(hook func(*DeliveryError)) Option {
+	return func(o *options) {
+		o.errorHook = hook
+	}
+}
+
+// WithJournal appends every published event to j before it is delivered,
+// so events can be replayed later with Replay. The bus does not close j.
+func WithJournal(j *Journal) Option {
+	return func(o *options) {
+		o.journal = j
+	}
 }
 
 // Bus manages event pub/sub.
@@ -27,7 +65,13 @@ type Bus struct {
 	mu          sync.RWMutex
 	handlers    map[Type][]Subscription
 	allHandlers []Subscription
+	patterns    *patternTrie
+	queues      map[string]*subscriberQueue
 	nextID      int
+	errorHook   func(*DeliveryError)
+	journal     *Journal
+	publishMW   []Middleware
+	deliveryMW  []Middleware
 	// semaphore limits concurrent goroutines in PublishAsync
 	semaphore chan struct{}
 	// wg tracks active async publishes for graceful shutdown
@@ -39,62 +83,153 @@ type Bus struct {
 }
 
 // NewBus creates a new event bus.
-func NewBus() *Bus {
+func NewBus(opts ...Option) *Bus {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Bus{
 		handlers:    make(map[Type][]Subscription),
 		allHandlers: make([]Subscription, 0),
+		patterns:    newPatternTrie(),
+		queues:      make(map[string]*subscriberQueue),
+		errorHook:   o.errorHook,
+		journal:     o.journal,
+		publishMW:   o.publishMW,
+		deliveryMW:  o.deliveryMW,
 		semaphore:   make(chan struct{}, maxAsyncPublishes),
 		ctx:         ctx,
 		cancel:      cancel,
 	}
 }
 
-// Subscribe registers a handler for a specific event type.
-// Returns subscription ID for later unsubscription.
-func (b *Bus) Subscribe(eventType Type, handler Handler) string {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// newSubscriptionLocked assigns an ID to a new subscription and starts its
+// queue worker if requested. Exactly one of handler and errHandler is set.
+// Must be called with b.mu held.
+func (b *Bus) newSubscriptionLocked(eventType Type, handler Handler, errHandler ErrorHandler, opts []SubscribeOption) Subscription {
+	o := &subscribeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 
 	b.nextID++
-	id := fmt.Sprintf("sub_%d", b.nextID)
+
+	if errHandler == nil {
+		errHandler = func(e Event) error {
+			handler(e)
+
+			return nil
+		}
+	}
 
 	sub := Subscription{
-		ID:      id,
+		ID:      fmt.Sprintf("sub_%d", b.nextID),
 		Type:    eventType,
 		Handler: handler,
+		handle:  errHandler,
+	}
+
+	if o.queueSize > 0 {
+		sub.queue = newSubscriberQueue(o.queueSize, o.overflow)
+		b.queues[sub.ID] = sub.queue
+		go sub.queue.run(func(event Event) {
+			if err := b.deliver(sub, event); err != nil {
+				b.reportError(&DeliveryError{SubscriptionID: sub.ID, Type: event.Type, Err: err})
+			}
+		})
 	}
 
+	return sub
+}
+
+// Subscribe registers a handler for a specific event type.
+// Returns subscription ID for later unsubscription.
+func (b *Bus) Subscribe(eventType Type, handler Handler, opts ...SubscribeOption) string {
+	return b.subscribe(eventType, handler, nil, opts)
+}
+
+// SubscribeE registers an error-returning handler for a specific event type.
+// Returned errors are reported through the error hook and in PublishResult.
+// Options such as WithQueue apply to all Subscribe variants.
+// Returns subscription ID for later unsubscription.
+func (b *Bus) SubscribeE(eventType Type, handler ErrorHandler, opts ...SubscribeOption) string {
+	return b.subscribe(eventType, nil, handler, opts)
+}
+
+func (b *Bus) subscribe(eventType Type, handler Handler, errHandler ErrorHandler, opts []SubscribeOption) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := b.newSubscriptionLocked(eventType, handler, errHandler, opts)
 	b.handlers[eventType] = append(b.handlers[eventType], sub)
 
-	return id
+	return sub.ID
 }
 
 // SubscribeAll registers a handler for all events.
 // Returns subscription ID for later unsubscription.
-func (b *Bus) SubscribeAll(handler Handler) string {
+func (b *Bus) SubscribeAll(handler Handler, opts ...SubscribeOption) string {
+	return b.subscribeAll(handler, nil, opts)
+}
+
+// SubscribeAllE registers an error-returning handler for all events.
+// Returns subscription ID for later unsubscription.
+func (b *Bus) SubscribeAllE(handler ErrorHandler, opts ...SubscribeOption) string {
+	return b.subscribeAll(nil, handler, opts)
+}
+
+func (b *Bus) subscribeAll(handler Handler, errHandler ErrorHandler, opts []SubscribeOption) string {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.nextID++
-	id := fmt.Sprintf("sub_%d", b.nextID)
+	sub := b.newSubscriptionLocked("", handler, errHandler, opts)
+	b.allHandlers = append(b.allHandlers, sub)
 
-	sub := Subscription{
-		ID:      id,
-		Handler: handler,
-	}
+	return sub.ID
+}
 
-	b.allHandlers = append(b.allHandlers, sub)
+// SubscribePattern registers a handler for all event types matching pattern,
+// e.g. "task.*" or "task.**". See PatternSeparator for the pattern syntax.
+// Returns subscription ID for later unsubscription.
+func (b *Bus) SubscribePattern(pattern string, handler Handler, opts ...SubscribeOption) string {
+	return b.subscribePattern(pattern, handler, nil, opts)
+}
+
+// SubscribePatternE registers an error-returning handler for all event types
+// matching pattern. Returns subscription ID for later unsubscription.
+func (b *Bus) SubscribePatternE(pattern string, handler ErrorHandler, opts ...SubscribeOption) string {
+	return b.subscribePattern(pattern, nil, handler, opts)
+}
+
+func (b *Bus) subscribePattern(pattern string, handler Handler, errHandler ErrorHandler, opts []SubscribeOption) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := b.newSubscriptionLocked(Type(pattern), handler, errHandler, opts)
+	b.patterns.add(pattern, sub)
 
-	return id
+	return sub.ID
 }
 
-// Unsubscribe removes a handler by ID.
+// Unsubscribe removes a handler by ID. Events still waiting in the
+// subscription's queue are discarded.
 func (b *Bus) Unsubscribe(id string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if q, ok := b.queues[id]; ok {
+		q.close(false)
+		delete(b.queues, id)
+	}
+
+	// Remove from pattern handlers
+	if b.patterns.remove(id) {
+		return
+	}
+
 	// Remove from type-specific handlers
 	for eventType, subs := range b.handlers {
 		for i, sub := range subs {
@@ -123,32 +258,105 @@ func (b *Bus) Publish(e Eventer) {
 }
 
 // PublishRaw sends a raw event to all registered handlers.
+// Handler errors and panics are reported through the error hook.
 func (b *Bus) PublishRaw(event Event) {
-	b.mu.RLock()
-	// Pre-allocate capacity to avoid reallocations
-	capacity := len(b.allHandlers)
-	if subs, ok := b.handlers[event.Type]; ok {
-		capacity += len(subs)
+	b.PublishRawCollect(event)
+}
+
+// PublishCollect sends a typed event to all registered handlers and
+// reports which of them failed.
+func (b *Bus) PublishCollect(e Eventer) PublishResult {
+	return b.PublishRawCollect(e.ToEvent())
+}
+
+// PublishRawCollect sends a raw event to all registered handlers and
+// reports which of them failed. A handler that returns an error or panics
+// does not prevent the remaining handlers from running. Failures are also
+// passed to the error hook. Queued subscriptions only receive the event in
+// their queue, so their failures are reported through the error hook alone.
+func (b *Bus) PublishRawCollect(event Event) PublishResult {
+	if len(b.publishMW) == 0 {
+		return b.publish(event)
 	}
-	handlers := make([]Handler, 0, capacity)
 
-	// Type-specific handlers
-	if subs, ok := b.handlers[event.Type]; ok {
-		for _, sub := range subs {
-			handlers = append(handlers, sub.Handler)
-		}
+	var result PublishResult
+	chain(b.publishMW, func(e Event) {
+		result = b.publish(e)
+	})(event)
+
+	return result
+}
+
+// publish journals event and delivers it, after publish middleware.
+func (b *Bus) publish(event Event) PublishResult {
+	event.Seq = b.record(event)
+
+	// Call handlers outside lock to prevent deadlocks
+	return b.dispatch(b.subscribers(event.Type), event)
+}
+
+// record appends event to the journal, if any, and returns its sequence
+// number, or 0 if it was not journaled.
+func (b *Bus) record(event Event) uint64 {
+	if b.journal == nil {
+		return 0
 	}
 
-	// All-event handlers
-	for _, sub := range b.allHandlers {
-		handlers = append(handlers, sub.Handler)
+	seq, err := b.journal.Append(event)
+	if err != nil {
+		log.Error("eventbus journal append failed", "type", event.Type, log.Err(err))
 	}
-	b.mu.RUnlock()
 
-	// Call handlers outside lock to prevent deadlocks
-	for _, handler := range handlers {
-		handler(event)
+	return seq
+}
+
+// subscribers returns the subscriptions matching eventType: type-specific
+// handlers, then pattern handlers, then all-event handlers.
+func (b *Bus) subscribers(eventType Type) []Subscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	patternSubs := b.patterns.match(eventType)
+
+	// Pre-allocate capacity to avoid reallocations
+	typeSubs := b.handlers[eventType]
+	subs := make([]Subscription, 0, len(typeSubs)+len(patternSubs)+len(b.allHandlers))
+
+	subs = append(subs, typeSubs...)
+	subs = append(subs, patternSubs...)
+
+	return append(subs, b.allHandlers...)
+}
+
+// dispatch enqueues event for queued subscriptions and calls the others.
+func (b *Bus) dispatch(subs []Subscription, event Event) PublishResult {
+	var result PublishResult
+	for _, sub := range subs {
+		if sub.queue != nil {
+			if sub.queue.enqueue(event) {
+				result.Queued++
+			} else {
+				result.Dropped++
+			}
+
+			continue
+		}
+
+		if err := b.deliver(sub, event); err != nil {
+			failure := &DeliveryError{
+				SubscriptionID: sub.ID,
+				Type:           event.Type,
+				Err:            err,
+			}
+			result.Failed = append(result.Failed, failure)
+			b.reportError(failure)
+
+			continue
+		}
+		result.Delivered++
 	}
+
+	return result
 }
 
 // PublishAsync sends an event asynchronously.
@@ -157,8 +365,41 @@ func (b *Bus) PublishAsync(e Eventer) {
 }
 
 // PublishRawAsync sends a raw event asynchronously.
+// Queued subscriptions receive the event in their queue before
+// PublishRawAsync returns, which keeps their per-subscriber order.
+// Other handlers run in a goroutine limited by a semaphore.
+// Publish middleware runs before PublishRawAsync returns.
 // Uses Go 1.25's WaitGroup.Go() for cleaner goroutine management.
 func (b *Bus) PublishRawAsync(event Event) {
+	if len(b.publishMW) == 0 {
+		b.publishAsync(event)
+
+		return
+	}
+
+	chain(b.publishMW, b.publishAsync)(event)
+}
+
+// publishAsync journals event and delivers it asynchronously, after publish
+// middleware.
+func (b *Bus) publishAsync(event Event) {
+	event.Seq = b.record(event)
+
+	subs := b.subscribers(event.Type)
+
+	direct := subs[:0]
+	for _, sub := range subs {
+		if sub.queue != nil {
+			sub.queue.enqueue(event)
+
+			continue
+		}
+		direct = append(direct, sub)
+	}
+	if len(direct) == 0 {
+		return
+	}
+
 	b.wg.Go(func() {
 		// Acquire semaphore slot or exit if context cancelled
 		select {
@@ -167,8 +408,133 @@ func (b *Bus) PublishRawAsync(event Event) {
 		case <-b.ctx.Done():
 			return
 		}
-		b.PublishRaw(event)
+		b.dispatch(direct, event)
+	})
+}
+
+// Replay re-delivers journaled events with a sequence number of at least
+// fromSeq to handler, oldest first. Replayed events are not published to
+// other subscribers. Stops at the first error returned by handler; panics
+// are returned as *PanicError.
+func (b *Bus) Replay(fromSeq uint64, handler ErrorHandler) error {
+	if b.journal == nil {
+		return ErrNoJournal
+	}
+
+	return b.journal.Replay(fromSeq, replayTo(handler))
+}
+
+// ReplaySince re-delivers journaled events published at or after since to
+// handler, oldest first. See Replay.
+func (b *Bus) ReplaySince(since time.Time, handler ErrorHandler) error {
+	if b.journal == nil {
+		return ErrNoJournal
+	}
+
+	return b.journal.ReplaySince(since, replayTo(handler))
+}
+
+// SubscribeFrom registers an error-returning handler for all events that
+// first receives the journaled events with a sequence number of at least
+// fromSeq, oldest first, and then live events. Events published while the
+// replay runs are held back until it is done, and events already replayed are
+// not delivered again, so the handler sees every event once, without a gap.
+// A replay error or panic removes the subscription and is returned, as with
+// Replay; errors from held-back events are reported through the error hook.
+// Returns ErrNoJournal if the bus has no journal.
+func (b *Bus) SubscribeFrom(fromSeq uint64, handler ErrorHandler, opts ...SubscribeOption) (string, error) {
+	if b.journal == nil {
+		return "", ErrNoJournal
+	}
+
+	catchUp := &catchUp{handler: handler, fromSeq: fromSeq, replaying: true}
+	id := b.SubscribeAllE(catchUp.live, opts...)
+
+	upTo, err := b.journal.replay(func(r JournalRecord) bool { return r.Seq >= fromSeq }, replayTo(handler))
+	if err != nil {
+		b.Unsubscribe(id)
+
+		return "", err
+	}
+
+	catchUp.finish(upTo, func(event Event, err error) {
+		b.reportError(&DeliveryError{SubscriptionID: id, Type: event.Type, Err: err})
 	})
+
+	return id, nil
+}
+
+// catchUp holds back the live events of a SubscribeFrom subscription while
+// the journal is replayed.
+type catchUp struct {
+	handler ErrorHandler
+	fromSeq uint64
+
+	mu        sync.Mutex
+	replaying bool
+	held      []Event
+	// upTo is the last sequence number the replay could include.
+	upTo uint64
+}
+
+// live receives the subscription's live events.
+func (c *catchUp) live(event Event) error {
+	c.mu.Lock()
+	if c.replaying {
+		c.held = append(c.held, event)
+		c.mu.Unlock()
+
+		return nil
+	}
+	c.mu.Unlock()
+
+	if c.replayed(event) {
+		return nil
+	}
+
+	return c.handler(event)
+}
+
+// finish delivers the held-back events that were not replayed, then lets
+// live events through. Events arriving meanwhile are held back too, so
+// order is kept.
+func (c *catchUp) finish(upTo uint64, report func(Event, error)) {
+	sub := Subscription{handle: c.handler}
+
+	c.mu.Lock()
+	c.upTo = upTo
+	for len(c.held) > 0 {
+		held := c.held
+		c.held = nil
+		c.mu.Unlock()
+
+		for _, event := range held {
+			if c.replayed(event) {
+				continue
+			}
+			if err := callHandler(sub, event); err != nil {
+				report(event, err)
+			}
+		}
+
+		c.mu.Lock()
+	}
+	c.replaying = false
+	c.mu.Unlock()
+}
+
+// replayed reports whether event was covered by the replay. Events without
+// a sequence number were not journaled and are always delivered.
+func (c *catchUp) replayed(event Event) bool {
+	return event.Seq != 0 && (event.Seq <= c.upTo || event.Seq < c.fromSeq)
+}
+
+func replayTo(handler ErrorHandler) func(JournalRecord) error {
+	sub := Subscription{handle: handler}
+
+	return func(r JournalRecord) error {
+		return callHandler(sub, r.Event())
+	}
 }
 
 // HasSubscribers returns true if there are any subscribers for the given type.
@@ -180,7 +546,11 @@ func (b *Bus) HasSubscribers(eventType Type) bool {
 		return true
 	}
 
-	return len(b.handlers[eventType]) > 0
+	if len(b.handlers[eventType]) > 0 {
+		return true
+	}
+
+	return b.patterns.matches(eventType)
 }
 
 // Clear removes all subscriptions.
@@ -190,11 +560,32 @@ func (b *Bus) Clear() {
 
 	b.handlers = make(map[Type][]Subscription)
 	b.allHandlers = make([]Subscription, 0)
+	b.patterns = newPatternTrie()
+
+	for _, q := range b.queues {
+		q.close(false)
+	}
+	b.queues = make(map[string]*subscriberQueue)
 }
 
-// Shutdown gracefully shuts down the event bus, waiting for async publishes to complete.
+// Shutdown gracefully shuts down the event bus, waiting for async publishes
+// to complete and for subscriber queues to drain.
 func (b *Bus) Shutdown() {
 	b.cancel()
 	// Wait for all active async publishes to complete
 	b.wg.Wait()
+
+	b.mu.RLock()
+	queues := make([]*subscriberQueue, 0, len(b.queues))
+	for _, q := range b.queues {
+		queues = append(queues, q)
+	}
+	b.mu.RUnlock()
+
+	for _, q := range queues {
+		q.close(true)
+	}
+	for _, q := range queues {
+		<-q.done
+	}
 }
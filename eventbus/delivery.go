@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/valksor/go-toolkit/log"
+)
+
+// ErrorHandler processes events and reports failure by returning an error.
+type ErrorHandler func(Event) error
+
+// PanicError is reported when a handler panics. The panic is recovered so
+// the publisher and the remaining handlers are not affected.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// DeliveryError describes a handler that failed to process an event.
+type DeliveryError struct {
+	Err            error
+	SubscriptionID string
+	Type           Type
+}
+
+// Error implements the error interface.
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("subscription %s failed on %s: %v", e.SubscriptionID, e.Type, e.Err)
+}
+
+// Unwrap returns the underlying handler error.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// PublishResult summarizes a synchronous publish.
+type PublishResult struct {
+	// Failed lists the handlers that returned an error or panicked.
+	Failed []*DeliveryError
+	// Delivered counts handlers that completed without error.
+	Delivered int
+	// Queued counts queued subscriptions that accepted the event.
+	Queued int
+	// Dropped counts queued subscriptions that discarded the event
+	// because their queue was full or stopped.
+	Dropped int
+}
+
+// Err returns all delivery failures joined into one error, or nil.
+func (r PublishResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(r.Failed))
+	for i, failure := range r.Failed {
+		errs[i] = failure
+	}
+
+	return errors.Join(errs...)
+}
+
+// callHandler calls the subscription handler, converting a panic into a PanicError.
+func callHandler(sub Subscription, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return sub.handle(event)
+}
+
+// reportError passes a delivery failure to the configured hook, or logs it.
+func (b *Bus) reportError(failure *DeliveryError) {
+	if b.errorHook != nil {
+		b.errorHook(failure)
+
+		return
+	}
+
+	log.Error("eventbus handler failed",
+		"subscription", failure.SubscriptionID,
+		"type", failure.Type,
+		log.Err(failure.Err))
+}
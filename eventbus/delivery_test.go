@@ -0,0 +1,111 @@
+package eventbus
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestBus_PanicIsolation(t *testing.T) {
+	var failures []*DeliveryError
+	bus := NewBus(WithErrorHook(func(d *DeliveryError) {
+		failures = append(failures, d)
+	}))
+	defer bus.Shutdown()
+
+	bus.Subscribe("test", func(e Event) {
+		panic("boom")
+	})
+
+	called := false
+	bus.Subscribe("test", func(e Event) {
+		called = true
+	})
+
+	bus.PublishRaw(Event{Type: "test"})
+
+	if !called {
+		t.Fatal("expected handler after the panicking one to run")
+	}
+	if len(failures) != 1 {
+		t.Fatalf("expected 1 failure reported, got %d", len(failures))
+	}
+
+	var panicErr *PanicError
+	if !errors.As(failures[0], &panicErr) {
+		t.Fatalf("expected PanicError, got %v", failures[0].Err)
+	}
+	if panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Fatalf("unexpected panic error: %v", panicErr)
+	}
+}
+
+func TestBus_PublishRawCollect(t *testing.T) {
+	var hooked int
+	bus := NewBus(WithErrorHook(func(*DeliveryError) {
+		hooked++
+	}))
+	defer bus.Shutdown()
+
+	errFailed := errors.New("failed")
+
+	okID := bus.SubscribeE("task.created", func(e Event) error {
+		return nil
+	})
+	failID := bus.SubscribePatternE("task.*", func(e Event) error {
+		return errFailed
+	})
+	panicID := bus.SubscribeAllE(func(e Event) error {
+		panic(errFailed)
+	})
+	bus.Subscribe("task.created", func(e Event) {})
+
+	result := bus.PublishRawCollect(Event{Type: "task.created"})
+
+	if result.Delivered != 2 {
+		t.Fatalf("expected 2 delivered, got %d", result.Delivered)
+	}
+	if len(result.Failed) != 2 {
+		t.Fatalf("expected 2 failures, got %d", len(result.Failed))
+	}
+	if result.Failed[0].SubscriptionID != failID || result.Failed[1].SubscriptionID != panicID {
+		t.Fatalf("unexpected failed subscriptions: %s, %s", result.Failed[0].SubscriptionID, result.Failed[1].SubscriptionID)
+	}
+	if result.Failed[0].Type != "task.created" {
+		t.Fatalf("expected event type on failure, got %s", result.Failed[0].Type)
+	}
+	if hooked != 2 {
+		t.Fatalf("expected hook to be called twice, got %d", hooked)
+	}
+
+	err := result.Err()
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected joined error to wrap errFailed, got %v", err)
+	}
+
+	bus.Unsubscribe(failID)
+	bus.Unsubscribe(panicID)
+	bus.Unsubscribe(okID)
+
+	result = bus.PublishCollect(mockEventer{event: Event{Type: "task.created"}})
+	if result.Err() != nil || result.Delivered != 1 {
+		t.Fatalf("expected clean publish, got %+v", result)
+	}
+}
+
+func TestBus_AsyncPanicReported(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	bus := NewBus(WithErrorHook(func(*DeliveryError) {
+		wg.Done()
+	}))
+	defer bus.Shutdown()
+
+	bus.Subscribe("test", func(e Event) {
+		panic("async boom")
+	})
+
+	bus.PublishRawAsync(Event{Type: "test"})
+	wg.Wait()
+}
@@ -0,0 +1,372 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJournalMaxSize is the size at which the journal file is rotated.
+	DefaultJournalMaxSize = 10 * 1024 * 1024
+	// DefaultJournalMaxFiles is the number of rotated files kept besides the
+	// active one.
+	DefaultJournalMaxFiles = 5
+)
+
+// maxJournalLine bounds the size of a single journal record when reading.
+const maxJournalLine = 16 * 1024 * 1024
+
+// ErrJournalClosed is returned when appending to a closed journal.
+var ErrJournalClosed = errors.New("journal is closed")
+
+// JournalRecord is one journaled event.
+type JournalRecord struct {
+	Timestamp time.Time      `json:"ts"`
+	Data      map[string]any `json:"data,omitempty"`
+	Type      Type           `json:"type"`
+	Seq       uint64         `json:"seq"`
+}
+
+// Event returns the journaled event. Typed payloads are available through
+// Data only; see PayloadAs.
+func (r JournalRecord) Event() Event {
+	return Event{Type: r.Type, Timestamp: r.Timestamp, Data: r.Data, Seq: r.Seq}
+}
+
+// JournalOption configures a Journal.
+type JournalOption func(*journalOptions)
+
+type journalOptions struct {
+	maxSize  int64
+	maxFiles int
+}
+
+// WithMaxFileSize sets the size in bytes at which the journal is rotated.
+// Defaults to DefaultJournalMaxSize.
+func WithMaxFileSize(n int64) JournalOption {
+	return func(o *journalOptions) {
+		if n > 0 {
+			o.maxSize = n
+		}
+	}
+}
+
+// WithMaxFiles sets how many rotated files are kept besides the active one.
+// Older files are deleted. Defaults to DefaultJournalMaxFiles.
+func WithMaxFiles(n int) JournalOption {
+	return func(o *journalOptions) {
+		if n >= 0 {
+			o.maxFiles = n
+		}
+	}
+}
+
+// Journal is an append-only log of events stored as JSON lines.
+//
+// The active file is rotated once it exceeds the size limit: "events.jsonl"
+// becomes "events.jsonl.1", the previous ".1" becomes ".2" and so on, up to
+// the configured number of files. Sequence numbers keep increasing across
+// rotations and process restarts.
+//
+// A truncated last line, as left by a crash mid-write, is skipped when
+// reading.
+type Journal struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	lastSeq uint64
+}
+
+// OpenJournal opens or creates the journal at path. The parent directory is
+// created if needed, and the last sequence number is recovered from
+// existing files.
+func OpenJournal(path string, opts ...JournalOption) (*Journal, error) {
+	o := &journalOptions{
+		maxSize:  DefaultJournalMaxSize,
+		maxFiles: DefaultJournalMaxFiles,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating journal directory: %w", err)
+	}
+
+	j := &Journal{
+		path:     path,
+		maxSize:  o.maxSize,
+		maxFiles: o.maxFiles,
+	}
+
+	// Files are scanned oldest first, so the last record seen has the highest sequence
+	err := j.read(func(r JournalRecord) error {
+		j.lastSeq = r.Seq
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := j.openActive(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Path returns the path of the active journal file.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// LastSeq returns the sequence number of the last appended record, or 0.
+func (j *Journal) LastSeq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.lastSeq
+}
+
+// Append writes event to the journal and returns its sequence number.
+func (j *Journal) Append(event Event) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return 0, ErrJournalClosed
+	}
+
+	record := JournalRecord{
+		Seq:       j.lastSeq + 1,
+		Timestamp: event.Timestamp,
+		Type:      event.Type,
+		Data:      event.Data,
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return 0, fmt.Errorf("encoding journal record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if j.size > 0 && j.size+int64(len(line)) > j.maxSize {
+		if err := j.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return 0, fmt.Errorf("writing journal: %w", err)
+	}
+	j.lastSeq = record.Seq
+
+	return record.Seq, nil
+}
+
+// Replay calls fn for every journaled record with a sequence number of at
+// least fromSeq, oldest first. Stops at the first error returned by fn.
+// Records appended while replaying, including by fn, are not replayed.
+func (j *Journal) Replay(fromSeq uint64, fn func(JournalRecord) error) error {
+	_, err := j.replay(func(r JournalRecord) bool { return r.Seq >= fromSeq }, fn)
+
+	return err
+}
+
+// ReplaySince calls fn for every journaled record with a timestamp not
+// before since, oldest first. Stops at the first error returned by fn.
+func (j *Journal) ReplaySince(since time.Time, fn func(JournalRecord) error) error {
+	_, err := j.replay(func(r JournalRecord) bool { return !r.Timestamp.Before(since) }, fn)
+
+	return err
+}
+
+// replay collects the matching records, then calls fn for each of them.
+// fn runs without j.mu held, so it may append to the journal, for example by
+// publishing on a bus journaling to it; records appended meanwhile are not
+// replayed. Returns the last sequence number at the time the records were
+// collected.
+func (j *Journal) replay(match func(JournalRecord) bool, fn func(JournalRecord) error) (uint64, error) {
+	var records []JournalRecord
+
+	// Hold the lock so rotation cannot move files while they are read
+	j.mu.Lock()
+	lastSeq := j.lastSeq
+	err := j.read(func(r JournalRecord) error {
+		if match(r) {
+			records = append(records, r)
+		}
+
+		return nil
+	})
+	j.mu.Unlock()
+
+	if err != nil {
+		return lastSeq, err
+	}
+
+	for _, r := range records {
+		if err := fn(r); err != nil {
+			return lastSeq, err
+		}
+	}
+
+	return lastSeq, nil
+}
+
+// Close closes the journal. Further appends fail with ErrJournalClosed.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+
+	return err
+}
+
+// openActive opens the active file for appending.
+func (j *Journal) openActive() error {
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening journal: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("opening journal: %w", err)
+	}
+
+	j.file = f
+	j.size = info.Size()
+
+	// Terminate a line left incomplete by a crash so new records start cleanly
+	if j.size > 0 && !endsWithNewline(j.path, j.size) {
+		if _, err := f.Write([]byte{'\n'}); err != nil {
+			return fmt.Errorf("opening journal: %w", err)
+		}
+		j.size++
+	}
+
+	return nil
+}
+
+// rotate shifts the rotated files by one and starts a new active file.
+// Must be called with j.mu held.
+func (j *Journal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("rotating journal: %w", err)
+	}
+	j.file = nil
+
+	if j.maxFiles == 0 {
+		if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotating journal: %w", err)
+		}
+	} else {
+		_ = os.Remove(j.rotatedPath(j.maxFiles))
+		for i := j.maxFiles - 1; i >= 1; i-- {
+			if err := os.Rename(j.rotatedPath(i), j.rotatedPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("rotating journal: %w", err)
+			}
+		}
+		if err := os.Rename(j.path, j.rotatedPath(1)); err != nil {
+			return fmt.Errorf("rotating journal: %w", err)
+		}
+	}
+
+	return j.openActive()
+}
+
+func (j *Journal) rotatedPath(n int) string {
+	return fmt.Sprintf("%s.%d", j.path, n)
+}
+
+// read calls fn for every record in all journal files, oldest first.
+func (j *Journal) read(fn func(JournalRecord) error) error {
+	for i := j.maxFiles; i >= 0; i-- {
+		path := j.path
+		if i > 0 {
+			path = j.rotatedPath(i)
+		}
+
+		if err := readJournalFile(path, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readJournalFile calls fn for every valid record in path. A missing file
+// is treated as empty and malformed lines are skipped.
+func readJournalFile(path string, fn func(JournalRecord) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("reading journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJournalLine)
+
+	for scanner.Scan() {
+		var record JournalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Seq == 0 {
+			continue
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading journal: %w", err)
+	}
+
+	return nil
+}
+
+// endsWithNewline reports whether the file at path, of the given size,
+// ends with a newline.
+func endsWithNewline(path string, size int64) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, 1)
+	if _, err := f.ReadAt(buf, size-1); err != nil && !errors.Is(err, io.EOF) {
+		return true
+	}
+
+	return buf[0] == '\n'
+}
@@ -0,0 +1,321 @@
+package eventbus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func collectSeqs(t *testing.T, replay func(func(JournalRecord) error) error) []uint64 {
+	t.Helper()
+
+	var seqs []uint64
+	err := replay(func(r JournalRecord) error {
+		seqs = append(seqs, r.Seq)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	return seqs
+}
+
+func TestJournal_AppendAndReplay(t *testing.T) {
+	j, err := OpenJournal(filepath.Join(t.TempDir(), "events", "events.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		seq, err := j.Append(Event{
+			Type:      "task.step",
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Data:      map[string]any{"step": i},
+		})
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if seq != uint64(i+1) {
+			t.Fatalf("expected seq %d, got %d", i+1, seq)
+		}
+	}
+
+	seqs := collectSeqs(t, func(fn func(JournalRecord) error) error { return j.Replay(3, fn) })
+	if !slices.Equal(seqs, []uint64{3, 4, 5}) {
+		t.Fatalf("unexpected replay from seq 3: %v", seqs)
+	}
+
+	seqs = collectSeqs(t, func(fn func(JournalRecord) error) error {
+		return j.ReplaySince(base.Add(time.Minute), fn)
+	})
+	if !slices.Equal(seqs, []uint64{2, 3, 4, 5}) {
+		t.Fatalf("unexpected replay since: %v", seqs)
+	}
+
+	var first JournalRecord
+	errStop := errors.New("stop")
+	err = j.Replay(0, func(r JournalRecord) error {
+		first = r
+
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected replay to stop with handler error, got %v", err)
+	}
+	if first.Type != "task.step" || first.Data["step"] != float64(0) || !first.Timestamp.Equal(base) {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+}
+
+func TestJournal_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	j, err := OpenJournal(path, WithMaxFileSize(200), WithMaxFiles(2))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+
+	for range 20 {
+		if _, err := j.Append(Event{Type: "tick", Data: map[string]any{"padding": "xxxxxxxxxxxxxxxxxxxx"}}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Fatalf("expected rotated file .2: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no more than 2 rotated files, got %v", err)
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("stat %s: %v", p, err)
+		}
+		if info.Size() > 200 {
+			t.Fatalf("expected %s to stay within the size limit, got %d bytes", p, info.Size())
+		}
+	}
+
+	// Old records are gone, the retained ones are contiguous and end at the last seq
+	seqs := collectSeqs(t, func(fn func(JournalRecord) error) error { return j.Replay(0, fn) })
+	if len(seqs) == 0 || seqs[0] == 1 || seqs[len(seqs)-1] != 20 {
+		t.Fatalf("unexpected retained records: %v", seqs)
+	}
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] != seqs[i-1]+1 {
+			t.Fatalf("expected contiguous records, got %v", seqs)
+		}
+	}
+}
+
+func TestJournal_ReopenRecoversSeq(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	_, _ = j.Append(Event{Type: "a"})
+	_, _ = j.Append(Event{Type: "b"})
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := j.Append(Event{Type: "c"}); !errors.Is(err, ErrJournalClosed) {
+		t.Fatalf("expected ErrJournalClosed, got %v", err)
+	}
+
+	// Simulate a crash in the middle of writing a record
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = f.WriteString(`{"seq":3,"type":"trunc`)
+	_ = f.Close()
+
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+
+	if j.LastSeq() != 2 {
+		t.Fatalf("expected last seq 2, got %d", j.LastSeq())
+	}
+
+	seq, err := j.Append(Event{Type: "c"})
+	if err != nil || seq != 3 {
+		t.Fatalf("expected seq 3, got %d, %v", seq, err)
+	}
+
+	seqs := collectSeqs(t, func(fn func(JournalRecord) error) error { return j.Replay(0, fn) })
+	if !slices.Equal(seqs, []uint64{1, 2, 3}) {
+		t.Fatalf("expected truncated record to be skipped, got %v", seqs)
+	}
+}
+
+func TestBus_JournalAndReplay(t *testing.T) {
+	j, err := OpenJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+
+	bus := NewBus(WithJournal(j))
+
+	bus.PublishRaw(Event{Type: "task.started"})
+	PublishTyped(bus, "task.step", taskCreated{ID: "t1", Steps: 1})
+	bus.PublishRawAsync(Event{Type: "task.finished"})
+	bus.Shutdown()
+
+	var types []Type
+	err = bus.Replay(2, func(e Event) error {
+		types = append(types, e.Type)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if !slices.Equal(types, []Type{"task.step", "task.finished"}) {
+		t.Fatalf("unexpected replayed events: %v", types)
+	}
+
+	var step taskCreated
+	err = bus.ReplaySince(time.Time{}, func(e Event) error {
+		if e.Type == "task.step" {
+			var err error
+			step, err = PayloadAs[taskCreated](e)
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil || step.ID != "t1" {
+		t.Fatalf("expected typed payload to survive the journal, got %+v, %v", step, err)
+	}
+
+	var panicErr *PanicError
+	err = bus.Replay(0, func(e Event) error { panic("replay boom") })
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected PanicError, got %v", err)
+	}
+
+	if err := NewBus().Replay(0, func(Event) error { return nil }); !errors.Is(err, ErrNoJournal) {
+		t.Fatalf("expected ErrNoJournal, got %v", err)
+	}
+}
+
+func TestBus_ReplayHandlerPublishes(t *testing.T) {
+	j, err := OpenJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+
+	bus := NewBus(WithJournal(j))
+	defer bus.Shutdown()
+
+	bus.PublishRaw(Event{Type: "task.started"})
+	bus.PublishRaw(Event{Type: "task.finished"})
+
+	// Handlers may publish, which appends to the journal being replayed
+	done := make(chan error, 1)
+	go func() {
+		done <- bus.Replay(0, func(e Event) error {
+			bus.PublishRaw(Event{Type: "task.replayed", Data: map[string]any{"of": string(e.Type)}})
+
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Replay: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("deadlock publishing from a replay handler")
+	}
+
+	if seq := j.LastSeq(); seq != 4 {
+		t.Fatalf("expected 2 replayed events to be journaled, last seq %d", seq)
+	}
+}
+
+func TestBus_SubscribeFromWithoutGapsOrDuplicates(t *testing.T) {
+	j, err := OpenJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+
+	bus := NewBus(WithJournal(j))
+	defer bus.Shutdown()
+
+	for range 50 {
+		bus.PublishRaw(Event{Type: "task.step"})
+	}
+
+	// Keep publishing while the subscription replays and switches to live events
+	start := make(chan struct{})
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		<-start
+		for range 195 {
+			bus.PublishRaw(Event{Type: "task.step"})
+		}
+	}()
+
+	var (
+		mu   sync.Mutex
+		seqs []uint64
+	)
+	_, err = bus.SubscribeFrom(10, func(e Event) error {
+		mu.Lock()
+		seqs = append(seqs, e.Seq)
+		first := len(seqs) == 1
+		mu.Unlock()
+
+		if first {
+			close(start)
+			for range 5 {
+				bus.PublishRaw(Event{Type: "task.step"})
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SubscribeFrom: %v", err)
+	}
+	<-published
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(seqs) != 241 {
+		t.Fatalf("expected 241 events, got %d", len(seqs))
+	}
+	for i, seq := range seqs {
+		if seq != uint64(10+i) {
+			t.Fatalf("expected seq %d at %d, got %d", 10+i, i, seq)
+		}
+	}
+
+	if _, err := NewBus().SubscribeFrom(0, func(Event) error { return nil }); !errors.Is(err, ErrNoJournal) {
+		t.Fatalf("expected ErrNoJournal, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-toolkit/log"
+)
+
+// RedactedValue replaces values removed by Redact.
+const RedactedValue = "[REDACTED]"
+
+// Middleware wraps a Handler. It may modify the event before calling next,
+// or drop it by not calling next at all. Middleware must not modify the
+// event's Data map in place, since it is shared with the publisher and
+// other subscribers; copy it instead.
+type Middleware func(next Handler) Handler
+
+// WithPublishMiddleware adds middleware that runs once per published event,
+// before it is journaled and delivered. Changes made here are seen by every
+// subscriber. The first middleware is the outermost.
+func WithPublishMiddleware(mw ...Middleware) Option {
+	return func(o *options) {
+		o.publishMW = append(o.publishMW, mw...)
+	}
+}
+
+// WithDeliveryMiddleware adds middleware that runs around every handler
+// call, once per subscriber, including queued subscribers. The first
+// middleware is the outermost.
+func WithDeliveryMiddleware(mw ...Middleware) Option {
+	return func(o *options) {
+		o.deliveryMW = append(o.deliveryMW, mw...)
+	}
+}
+
+// chain wraps final with mw, the first middleware being the outermost.
+func chain(mw []Middleware, final Handler) Handler {
+	h := final
+	for _, m := range slices.Backward(mw) {
+		h = m(h)
+	}
+
+	return h
+}
+
+// deliver calls the subscription handler through the delivery middleware,
+// converting a panic into a PanicError.
+func (b *Bus) deliver(sub Subscription, event Event) error {
+	if len(b.deliveryMW) > 0 {
+		handle := sub.handle
+		sub.handle = func(e Event) error {
+			var err error
+			chain(b.deliveryMW, func(e Event) {
+				err = handle(e)
+			})(e)
+
+			return err
+		}
+	}
+
+	return callHandler(sub, event)
+}
+
+// DefaultTimestamp sets Event.Timestamp to the current time when it is zero.
+func DefaultTimestamp() Middleware {
+	return func(next Handler) Handler {
+		return func(e Event) {
+			if e.Timestamp.IsZero() {
+				e.Timestamp = time.Now()
+			}
+			next(e)
+		}
+	}
+}
+
+// LogEvents logs every event at level through the log package.
+// Use it as publish middleware to log each event once, or as delivery
+// middleware to log each handler call.
+func LogEvents(level slog.Level) Middleware {
+	return func(next Handler) Handler {
+		return func(e Event) {
+			logger := log.Logger()
+			if logger.Enabled(context.Background(), level) {
+				logger.Log(context.Background(), level, "eventbus event",
+					"type", e.Type,
+					"timestamp", e.Timestamp,
+					"data", e.Data)
+			}
+			next(e)
+		}
+	}
+}
+
+// Sample passes on a random fraction rate of events (0 drops all, 1 keeps
+// all). If types are given, only events of those types are sampled and all
+// others pass through.
+func Sample(rate float64, types ...Type) Middleware {
+	return func(next Handler) Handler {
+		return func(e Event) {
+			if len(types) > 0 && !slices.Contains(types, e.Type) {
+				next(e)
+
+				return
+			}
+			if rand.Float64() < rate { //nolint:gosec // sampling needs no cryptographic randomness
+				next(e)
+			}
+		}
+	}
+}
+
+// Enrich adds fields to Data. Keys already present in the event are kept.
+func Enrich(fields map[string]any) Middleware {
+	return EnrichFunc(func(Event) map[string]any {
+		return fields
+	})
+}
+
+// EnrichFunc adds the fields returned by fn to Data, e.g. a trace ID.
+// Keys already present in the event are kept.
+func EnrichFunc(fn func(Event) map[string]any) Middleware {
+	return func(next Handler) Handler {
+		return func(e Event) {
+			fields := fn(e)
+			if len(fields) > 0 {
+				data := make(map[string]any, len(e.Data)+len(fields))
+				maps.Copy(data, fields)
+				maps.Copy(data, e.Data)
+				e.Data = data
+			}
+			next(e)
+		}
+	}
+}
+
+// Redact replaces the values of the given Data keys with RedactedValue,
+// including keys of nested maps. Keys are matched case-insensitively.
+// Typed payloads in Event.Payload are dropped, since they may hold the
+// same secrets; subscribers decode Data instead.
+func Redact(keys ...string) Middleware {
+	lowered := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		lowered[strings.ToLower(key)] = struct{}{}
+	}
+
+	return func(next Handler) Handler {
+		return func(e Event) {
+			if data, changed := redactMap(e.Data, lowered); changed {
+				e.Data = data
+				e.Payload = nil
+			}
+			next(e)
+		}
+	}
+}
+
+// redactMap returns a copy of data with secret keys redacted, or data itself
+// if nothing needed redacting.
+func redactMap(data map[string]any, keys map[string]struct{}) (map[string]any, bool) {
+	var out map[string]any
+	for key, value := range data {
+		replacement := value
+		if _, secret := keys[strings.ToLower(key)]; secret {
+			replacement = RedactedValue
+		} else if nested, ok := value.(map[string]any); ok {
+			redacted, changed := redactMap(nested, keys)
+			if !changed {
+				continue
+			}
+			replacement = redacted
+		} else {
+			continue
+		}
+
+		if out == nil {
+			out = maps.Clone(data)
+		}
+		out[key] = replacement
+	}
+
+	if out == nil {
+		return data, false
+	}
+
+	return out, true
+}
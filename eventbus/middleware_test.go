@@ -0,0 +1,169 @@
+package eventbus
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-toolkit/log"
+)
+
+func TestBus_PublishMiddleware(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(e Event) {
+				order = append(order, name)
+				next(e)
+			}
+		}
+	}
+
+	bus := NewBus(WithPublishMiddleware(
+		trace("outer"),
+		trace("inner"),
+		DefaultTimestamp(),
+		Enrich(map[string]any{"project": "toolkit", "task_id": "default"}),
+		EnrichFunc(func(e Event) map[string]any {
+			return map[string]any{"trace_id": "trace-" + string(e.Type)}
+		}),
+		Redact("token"),
+	))
+	defer bus.Shutdown()
+
+	var received []Event
+	bus.Subscribe("task.created", func(e Event) {
+		order = append(order, "handler")
+		received = append(received, e)
+	})
+	bus.Subscribe("task.created", func(e Event) {
+		received = append(received, e)
+	})
+
+	data := map[string]any{
+		"task_id": "t1",
+		"auth":    map[string]any{"Token": "secret", "user": "alice"},
+	}
+	bus.PublishRaw(Event{Type: "task.created", Data: data})
+
+	if !slices.Equal(order, []string{"outer", "inner", "handler"}) {
+		t.Fatalf("unexpected order: %v", order)
+	}
+
+	e := received[0]
+	if e.Timestamp.IsZero() {
+		t.Fatal("expected timestamp to be defaulted")
+	}
+	if e.Data["project"] != "toolkit" || e.Data["trace_id"] != "trace-task.created" {
+		t.Fatalf("expected enriched data, got %v", e.Data)
+	}
+	if e.Data["task_id"] != "t1" {
+		t.Fatalf("expected existing keys to win over enrichment, got %v", e.Data["task_id"])
+	}
+	auth := e.Data["auth"].(map[string]any)
+	if auth["Token"] != RedactedValue || auth["user"] != "alice" {
+		t.Fatalf("expected nested token to be redacted, got %v", auth)
+	}
+
+	// Publish middleware runs once, so every subscriber sees the same event
+	if received[1].Data["trace_id"] != e.Data["trace_id"] {
+		t.Fatal("expected subscribers to share the published event")
+	}
+
+	// The publisher's data is untouched
+	if data["auth"].(map[string]any)["Token"] != "secret" {
+		t.Fatal("expected publisher data not to be modified")
+	}
+	if _, ok := data["project"]; ok {
+		t.Fatal("expected publisher data not to be enriched in place")
+	}
+}
+
+func TestBus_DeliveryMiddleware(t *testing.T) {
+	calls := 0
+	counting := func(next Handler) Handler {
+		return func(e Event) {
+			calls++
+			next(e)
+		}
+	}
+
+	// Skip delivery of events marked internal
+	filter := func(next Handler) Handler {
+		return func(e Event) {
+			if e.Data["internal"] == true {
+				return
+			}
+			next(e)
+		}
+	}
+
+	var hooked []*DeliveryError
+	bus := NewBus(
+		WithDeliveryMiddleware(counting, filter),
+		WithErrorHook(func(d *DeliveryError) { hooked = append(hooked, d) }),
+	)
+	defer bus.Shutdown()
+
+	handled := 0
+	bus.Subscribe("test", func(e Event) { handled++ })
+	bus.SubscribeE("test", func(e Event) error {
+		panic("boom")
+	})
+
+	result := bus.PublishRawCollect(Event{Type: "test"})
+	bus.PublishRaw(Event{Type: "test", Data: map[string]any{"internal": true}})
+
+	if calls != 4 {
+		t.Fatalf("expected middleware to run per subscriber, got %d calls", calls)
+	}
+	if handled != 1 {
+		t.Fatalf("expected filtered event to be skipped, got %d", handled)
+	}
+	if len(result.Failed) != 1 || len(hooked) != 1 {
+		t.Fatalf("expected panic through middleware to be reported once, got %+v", result)
+	}
+}
+
+func TestSample(t *testing.T) {
+	bus := NewBus(WithPublishMiddleware(Sample(0, "task.progress")))
+	defer bus.Shutdown()
+
+	var types []Type
+	bus.SubscribeAll(func(e Event) { types = append(types, e.Type) })
+
+	for range 10 {
+		bus.PublishRaw(Event{Type: "task.progress"})
+	}
+	bus.PublishRaw(Event{Type: "task.done"})
+
+	if !slices.Equal(types, []Type{"task.done"}) {
+		t.Fatalf("expected only unsampled types, got %v", types)
+	}
+
+	kept := 0
+	keepAll := Sample(1)(func(Event) { kept++ })
+	for range 10 {
+		keepAll(Event{Type: "x"})
+	}
+	if kept != 10 {
+		t.Fatalf("expected rate 1 to keep everything, got %d", kept)
+	}
+}
+
+func TestLogEvents(t *testing.T) {
+	var buf bytes.Buffer
+	log.Configure(log.Options{Output: &buf, Level: log.LevelDebug})
+	defer log.Configure(log.Options{})
+
+	bus := NewBus(WithPublishMiddleware(LogEvents(slog.LevelDebug)))
+	defer bus.Shutdown()
+
+	bus.PublishRaw(Event{Type: "task.created"})
+
+	if !strings.Contains(buf.String(), "task.created") {
+		t.Fatalf("expected event to be logged, got %q", buf.String())
+	}
+}
@@ -0,0 +1,153 @@
+package eventbus
+
+import "strings"
+
+// Pattern syntax for SubscribePattern.
+//
+// Event types are matched segment by segment, where segments are separated
+// by PatternSeparator:
+//   - "*" matches exactly one segment ("task.*" matches "task.created")
+//   - "**" matches zero or more segments ("task.**" matches "task",
+//     "task.created" and "task.step.started")
+//   - any other segment matches itself
+const (
+	PatternSeparator = "."
+	wildcardOne      = "*"
+	wildcardMany     = "**"
+)
+
+// patternNode is a node of the subscription trie.
+type patternNode struct {
+	children map[string]*patternNode
+	subs     []Subscription
+}
+
+// patternTrie indexes pattern subscriptions so that matching an event type
+// only visits the branches that can match it.
+type patternTrie struct {
+	root *patternNode
+	// patterns maps subscription IDs to their pattern for unsubscription.
+	patterns map[string]string
+}
+
+func newPatternTrie() *patternTrie {
+	return &patternTrie{
+		root:     &patternNode{},
+		patterns: make(map[string]string),
+	}
+}
+
+func (t *patternTrie) add(pattern string, sub Subscription) {
+	node := t.root
+	for _, seg := range strings.Split(pattern, PatternSeparator) {
+		child, ok := node.children[seg]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*patternNode)
+			}
+			child = &patternNode{}
+			node.children[seg] = child
+		}
+		node = child
+	}
+
+	node.subs = append(node.subs, sub)
+	t.patterns[sub.ID] = pattern
+}
+
+// remove deletes the subscription with the given ID. Returns false if unknown.
+func (t *patternTrie) remove(id string) bool {
+	pattern, ok := t.patterns[id]
+	if !ok {
+		return false
+	}
+	delete(t.patterns, id)
+
+	segs := strings.Split(pattern, PatternSeparator)
+	path := make([]*patternNode, 0, len(segs)+1)
+
+	node := t.root
+	path = append(path, node)
+	for _, seg := range segs {
+		node = node.children[seg]
+		path = append(path, node)
+	}
+
+	for i, sub := range node.subs {
+		if sub.ID == id {
+			node.subs = append(node.subs[:i:i], node.subs[i+1:]...)
+
+			break
+		}
+	}
+
+	// Prune nodes that no longer hold subscriptions
+	for i := len(segs) - 1; i >= 0; i-- {
+		child := path[i+1]
+		if len(child.subs) > 0 || len(child.children) > 0 {
+			break
+		}
+		delete(path[i].children, segs[i])
+	}
+
+	return true
+}
+
+// match returns the subscriptions whose pattern matches eventType.
+// Each subscription is returned at most once.
+func (t *patternTrie) match(eventType Type) []Subscription {
+	if len(t.patterns) == 0 {
+		return nil
+	}
+
+	var subs []Subscription
+	seen := make(map[string]struct{})
+	t.root.match(strings.Split(string(eventType), PatternSeparator), func(s []Subscription) {
+		for _, sub := range s {
+			if _, ok := seen[sub.ID]; !ok {
+				seen[sub.ID] = struct{}{}
+				subs = append(subs, sub)
+			}
+		}
+	})
+
+	return subs
+}
+
+// matches returns true if any pattern matches eventType.
+func (t *patternTrie) matches(eventType Type) bool {
+	if len(t.patterns) == 0 {
+		return false
+	}
+
+	found := false
+	t.root.match(strings.Split(string(eventType), PatternSeparator), func(s []Subscription) {
+		if len(s) > 0 {
+			found = true
+		}
+	})
+
+	return found
+}
+
+// match walks the trie for the remaining segments and reports the
+// subscriptions of every node that matches them completely.
+func (n *patternNode) match(segs []string, visit func([]Subscription)) {
+	if len(segs) == 0 {
+		visit(n.subs)
+	} else {
+		if child, ok := n.children[segs[0]]; ok {
+			child.match(segs[1:], visit)
+		}
+		if child, ok := n.children[wildcardOne]; ok {
+			child.match(segs[1:], visit)
+		}
+	}
+
+	// "**" consumes zero or more of the remaining segments
+	if child, ok := n.children[wildcardMany]; ok {
+		for i := 0; i <= len(segs); i++ {
+			child.match(segs[i:], visit)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package eventbus
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPatternTrie_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		matches []Type
+		misses  []Type
+	}{
+		{
+			pattern: "task.created",
+			matches: []Type{"task.created"},
+			misses:  []Type{"task", "task.created.now", "task.deleted"},
+		},
+		{
+			pattern: "task.*",
+			matches: []Type{"task.created", "task.deleted"},
+			misses:  []Type{"task", "task.step.started", "agent.created"},
+		},
+		{
+			pattern: "task.**",
+			matches: []Type{"task", "task.created", "task.step.started"},
+			misses:  []Type{"agent.created", "tasks.created"},
+		},
+		{
+			pattern: "*.started",
+			matches: []Type{"task.started", "agent.started"},
+			misses:  []Type{"task.step.started", "started"},
+		},
+		{
+			pattern: "task.**.started",
+			matches: []Type{"task.started", "task.step.started", "task.a.b.started"},
+			misses:  []Type{"task.step.finished"},
+		},
+		{
+			pattern: "**",
+			matches: []Type{"task", "task.created", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			trie := newPatternTrie()
+			trie.add(tt.pattern, Subscription{ID: "sub"})
+
+			for _, typ := range tt.matches {
+				if len(trie.match(typ)) != 1 {
+					t.Errorf("expected %q to match %q", tt.pattern, typ)
+				}
+				if !trie.matches(typ) {
+					t.Errorf("expected matches(%q) to be true for %q", typ, tt.pattern)
+				}
+			}
+			for _, typ := range tt.misses {
+				if len(trie.match(typ)) != 0 {
+					t.Errorf("expected %q not to match %q", tt.pattern, typ)
+				}
+				if trie.matches(typ) {
+					t.Errorf("expected matches(%q) to be false for %q", typ, tt.pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestPatternTrie_NoDuplicates(t *testing.T) {
+	trie := newPatternTrie()
+	trie.add("a.**.**", Subscription{ID: "sub"})
+
+	if n := len(trie.match("a.b.c")); n != 1 {
+		t.Fatalf("expected subscription to be matched once, got %d", n)
+	}
+}
+
+func TestPatternTrie_Remove(t *testing.T) {
+	trie := newPatternTrie()
+	trie.add("task.*", Subscription{ID: "a"})
+	trie.add("task.*", Subscription{ID: "b"})
+	trie.add("task.step.*", Subscription{ID: "c"})
+
+	if !trie.remove("a") {
+		t.Fatal("expected a to be removed")
+	}
+	if trie.remove("a") {
+		t.Fatal("expected second remove to report unknown ID")
+	}
+
+	ids := func(typ Type) []string {
+		var out []string
+		for _, sub := range trie.match(typ) {
+			out = append(out, sub.ID)
+		}
+
+		return out
+	}
+
+	if got := ids("task.created"); !slices.Equal(got, []string{"b"}) {
+		t.Fatalf("expected [b], got %v", got)
+	}
+
+	trie.remove("c")
+	if _, ok := trie.root.children["task"].children["step"]; ok {
+		t.Fatal("expected empty branch to be pruned")
+	}
+
+	trie.remove("b")
+	if len(trie.root.children) != 0 {
+		t.Fatal("expected trie to be empty")
+	}
+}
+
+func TestBus_SubscribePattern(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	var received []Type
+	bus.SubscribePattern("task.*", func(e Event) {
+		received = append(received, e.Type)
+	})
+
+	var deep []Type
+	bus.SubscribePattern("task.**", func(e Event) {
+		deep = append(deep, e.Type)
+	})
+
+	for _, typ := range []Type{"task.created", "task.step.started", "agent.finished"} {
+		bus.PublishRaw(Event{Type: typ})
+	}
+
+	if !slices.Equal(received, []Type{"task.created"}) {
+		t.Fatalf("unexpected events for task.*: %v", received)
+	}
+	if !slices.Equal(deep, []Type{"task.created", "task.step.started"}) {
+		t.Fatalf("unexpected events for task.**: %v", deep)
+	}
+}
+
+func TestBus_PatternHasSubscribers(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	id := bus.SubscribePattern("task.**", func(e Event) {})
+
+	if !bus.HasSubscribers("task.step.started") {
+		t.Fatal("expected pattern subscription to count")
+	}
+	if bus.HasSubscribers("agent.started") {
+		t.Fatal("expected no subscribers for agent.started")
+	}
+
+	bus.Unsubscribe(id)
+	if bus.HasSubscribers("task.step.started") {
+		t.Fatal("expected no subscribers after unsubscribe")
+	}
+
+	bus.SubscribePattern("task.*", func(e Event) {})
+	bus.Clear()
+	if bus.HasSubscribers("task.created") {
+		t.Fatal("expected no subscribers after Clear")
+	}
+}
+
+func BenchmarkBus_PublishRawWithPatterns(b *testing.B) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	for i := range 100 {
+		bus.SubscribePattern("other"+string(rune('a'+i%26))+".*", func(e Event) {})
+	}
+	bus.SubscribePattern("task.**", func(e Event) {})
+
+	event := Event{Type: "task.step.started"}
+
+	b.ReportAllocs()
+	for range b.N {
+		bus.PublishRaw(event)
+	}
+}
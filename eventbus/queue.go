@@ -0,0 +1,223 @@
+package eventbus
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens when a subscriber queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the publisher wait until the queue has room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued event to make room.
+	OverflowDropOldest
+	// OverflowDropNewest discards the event being published.
+	OverflowDropNewest
+)
+
+// String returns the policy name.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	default:
+		return "unknown"
+	}
+}
+
+// SubscribeOption configures a single subscription.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	queueSize int
+	overflow  OverflowPolicy
+}
+
+// WithQueue delivers events to the subscription through a buffered queue of
+// the given size, drained by a dedicated goroutine. Events reach the handler
+// in publish order and a slow handler does not delay publishers or other
+// subscribers until its queue is full; policy decides what happens then.
+//
+// Both Publish and PublishAsync only enqueue for queued subscriptions, so
+// with OverflowBlock even PublishAsync waits for room in the queue.
+func WithQueue(size int, policy OverflowPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if size > 0 {
+			o.queueSize = size
+			o.overflow = policy
+		}
+	}
+}
+
+// QueueStats is a point-in-time snapshot of a subscriber queue.
+type QueueStats struct {
+	SubscriptionID string
+	Policy         OverflowPolicy
+	// Depth is the number of events waiting in the queue.
+	Depth int
+	// MaxDepth is the highest depth observed.
+	MaxDepth int
+	// Capacity is the queue size.
+	Capacity  int
+	Enqueued  uint64
+	Delivered uint64
+	Dropped   uint64
+}
+
+// subscriberQueue buffers events for one subscription.
+type subscriberQueue struct {
+	events chan Event
+	policy OverflowPolicy
+	// stop is closed to end the worker; drain tells it whether to deliver
+	// the events still queued first.
+	stop      chan struct{}
+	stopped   atomic.Bool
+	drain     atomic.Bool
+	done      chan struct{}
+	maxDepth  atomic.Int64
+	enqueued  atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+func newSubscriberQueue(size int, policy OverflowPolicy) *subscriberQueue {
+	return &subscriberQueue{
+		events: make(chan Event, size),
+		policy: policy,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// run delivers queued events in order until the queue is stopped.
+func (q *subscriberQueue) run(handle func(Event)) {
+	defer close(q.done)
+
+	for {
+		select {
+		case event := <-q.events:
+			handle(event)
+			q.delivered.Add(1)
+		case <-q.stop:
+			if q.drain.Load() {
+				for {
+					select {
+					case event := <-q.events:
+						handle(event)
+						q.delivered.Add(1)
+					default:
+						return
+					}
+				}
+			}
+
+			return
+		}
+	}
+}
+
+// enqueue adds event according to the overflow policy.
+// Returns false if the event was dropped.
+func (q *subscriberQueue) enqueue(event Event) bool {
+	if q.stopped.Load() {
+		return false
+	}
+
+	switch q.policy {
+	case OverflowDropNewest:
+		select {
+		case q.events <- event:
+		default:
+			q.dropped.Add(1)
+
+			return false
+		}
+	case OverflowDropOldest:
+		for sent := false; !sent; {
+			select {
+			case q.events <- event:
+				sent = true
+			default:
+				select {
+				case <-q.events:
+					q.dropped.Add(1)
+				default:
+				}
+			}
+		}
+	default:
+		select {
+		case q.events <- event:
+		case <-q.stop:
+			q.dropped.Add(1)
+
+			return false
+		}
+	}
+
+	q.enqueued.Add(1)
+	q.observeDepth()
+
+	return true
+}
+
+func (q *subscriberQueue) observeDepth() {
+	depth := int64(len(q.events))
+	for {
+		peak := q.maxDepth.Load()
+		if depth <= peak || q.maxDepth.CompareAndSwap(peak, depth) {
+			return
+		}
+	}
+}
+
+// close stops the worker. If drain is true, queued events are delivered first.
+// It does not wait for the worker to finish.
+func (q *subscriberQueue) close(drain bool) {
+	if q.stopped.Swap(true) {
+		return
+	}
+	q.drain.Store(drain)
+	close(q.stop)
+}
+
+func (q *subscriberQueue) stats(id string) QueueStats {
+	return QueueStats{
+		SubscriptionID: id,
+		Policy:         q.policy,
+		Depth:          len(q.events),
+		MaxDepth:       int(q.maxDepth.Load()),
+		Capacity:       cap(q.events),
+		Enqueued:       q.enqueued.Load(),
+		Delivered:      q.delivered.Load(),
+		Dropped:        q.dropped.Load(),
+	}
+}
+
+// QueueStats returns a snapshot of every subscriber queue, ordered by
+// subscription ID.
+func (b *Bus) QueueStats() []QueueStats {
+	b.mu.RLock()
+	stats := make([]QueueStats, 0, len(b.queues))
+	for id, q := range b.queues {
+		stats = append(stats, q.stats(id))
+	}
+	b.mu.RUnlock()
+
+	slices.SortFunc(stats, func(a, b QueueStats) int {
+		if len(a.SubscriptionID) != len(b.SubscriptionID) {
+			return len(a.SubscriptionID) - len(b.SubscriptionID)
+		}
+
+		return strings.Compare(a.SubscriptionID, b.SubscriptionID)
+	})
+
+	return stats
+}
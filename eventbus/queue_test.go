@@ -0,0 +1,207 @@
+package eventbus
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue_FIFOWithAsyncPublish(t *testing.T) {
+	bus := NewBus()
+
+	var mu sync.Mutex
+	var got []int
+	bus.Subscribe("test", func(e Event) {
+		mu.Lock()
+		got = append(got, e.Data["n"].(int))
+		mu.Unlock()
+	}, WithQueue(16, OverflowBlock))
+
+	want := make([]int, 200)
+	for i := range want {
+		want[i] = i
+		bus.PublishRawAsync(Event{Type: "test", Data: map[string]any{"n": i}})
+	}
+
+	bus.Shutdown()
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected events in publish order, got %v", got)
+	}
+}
+
+func TestQueue_SlowSubscriberDoesNotStallOthers(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	release := make(chan struct{})
+	bus.Subscribe("test", func(e Event) {
+		<-release
+	}, WithQueue(4, OverflowDropNewest))
+
+	fast := 0
+	bus.Subscribe("test", func(e Event) {
+		fast++
+	})
+
+	done := make(chan struct{})
+	go func() {
+		for range 10 {
+			bus.PublishRaw(Event{Type: "test"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher stalled by slow subscriber")
+	}
+	close(release)
+
+	if fast != 10 {
+		t.Fatalf("expected fast subscriber to get 10 events, got %d", fast)
+	}
+}
+
+func TestQueue_OverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   []int
+	}{
+		{OverflowDropNewest, []int{0, 1}},
+		{OverflowDropOldest, []int{3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			bus := NewBus()
+
+			block := make(chan struct{})
+			started := make(chan struct{})
+			var got []int
+			id := bus.Subscribe("test", func(e Event) {
+				n := e.Data["n"].(int)
+				if n == -1 {
+					close(started)
+					<-block
+
+					return
+				}
+				got = append(got, n)
+			}, WithQueue(2, tt.policy))
+
+			// Occupy the worker so the following events stay queued
+			bus.PublishRaw(Event{Type: "test", Data: map[string]any{"n": -1}})
+			<-started
+
+			var dropped int
+			for i := range 5 {
+				dropped += bus.PublishRawCollect(Event{Type: "test", Data: map[string]any{"n": i}}).Dropped
+			}
+
+			stats := bus.QueueStats()
+			if len(stats) != 1 || stats[0].SubscriptionID != id {
+				t.Fatalf("unexpected queue stats: %+v", stats)
+			}
+			if stats[0].Depth != 2 || stats[0].MaxDepth != 2 || stats[0].Capacity != 2 {
+				t.Fatalf("unexpected depth: %+v", stats[0])
+			}
+			if stats[0].Dropped != 3 {
+				t.Fatalf("expected 3 dropped, got %d", stats[0].Dropped)
+			}
+			if tt.policy == OverflowDropNewest && dropped != 3 {
+				t.Fatalf("expected 3 drops in publish results, got %d", dropped)
+			}
+
+			close(block)
+			bus.Shutdown()
+
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			if stats := bus.QueueStats(); stats[0].Delivered != 3 || stats[0].Depth != 0 {
+				t.Fatalf("unexpected stats after drain: %+v", stats[0])
+			}
+		})
+	}
+}
+
+func TestQueue_BlockWaitsForRoom(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	release := make(chan struct{})
+	bus.Subscribe("test", func(e Event) {
+		<-release
+	}, WithQueue(1, OverflowBlock))
+
+	done := make(chan struct{})
+	go func() {
+		for range 3 {
+			bus.PublishRaw(Event{Type: "test"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected publisher to block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+}
+
+func TestQueue_UnsubscribeStopsDelivery(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	var mu sync.Mutex
+	count := 0
+	id := bus.Subscribe("test", func(e Event) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}, WithQueue(8, OverflowBlock))
+
+	bus.Unsubscribe(id)
+	result := bus.PublishRawCollect(Event{Type: "test"})
+
+	if result.Queued != 0 || len(bus.QueueStats()) != 0 {
+		t.Fatalf("expected no queued subscriptions, got %+v", result)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if count != 0 {
+		t.Fatalf("expected no deliveries, got %d", count)
+	}
+}
+
+func TestQueue_ErrorsReachHook(t *testing.T) {
+	failures := make(chan *DeliveryError, 1)
+	bus := NewBus(WithErrorHook(func(d *DeliveryError) {
+		failures <- d
+	}))
+	defer bus.Shutdown()
+
+	id := bus.SubscribeE("test", func(e Event) error {
+		panic("queued boom")
+	}, WithQueue(1, OverflowBlock))
+
+	result := bus.PublishRawCollect(Event{Type: "test"})
+	if result.Queued != 1 || result.Err() != nil {
+		t.Fatalf("expected event to be queued without error, got %+v", result)
+	}
+
+	select {
+	case d := <-failures:
+		if d.SubscriptionID != id {
+			t.Fatalf("unexpected subscription %s", d.SubscriptionID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected queued failure to reach the error hook")
+	}
+}
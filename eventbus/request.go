@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"maps"
+)
+
+// CorrelationIDKey is the Event.Data key holding the correlation ID that
+// links a reply to its request.
+const CorrelationIDKey = "correlation_id"
+
+// WaitFor blocks until an event of eventType satisfying predicate is
+// published, or ctx is done. A nil predicate accepts any event of the type.
+// The temporary subscription is removed before WaitFor returns.
+//
+// WaitFor only sees events published after it subscribes. To publish an
+// event and wait for its outcome without a race, use Request.
+func (b *Bus) WaitFor(ctx context.Context, eventType Type, predicate func(Event) bool) (Event, error) {
+	matched, id := b.await(eventType, predicate)
+	defer b.Unsubscribe(id)
+
+	return wait(ctx, eventType, matched)
+}
+
+// Request publishes req and waits for an event of replyType carrying the
+// same correlation ID, or until ctx is done. If req has no correlation ID
+// in its Data, a new one is assigned; the caller's Data map is not modified.
+// Responders answer with Reply.
+func (b *Bus) Request(ctx context.Context, req Event, replyType Type) (Event, error) {
+	id := CorrelationID(req)
+	if id == "" {
+		id = rand.Text()
+		req.Data = withCorrelationID(req.Data, id)
+	}
+
+	// Subscribe before publishing so a synchronous reply is not missed
+	matched, subID := b.await(replyType, func(e Event) bool {
+		return CorrelationID(e) == id
+	})
+	defer b.Unsubscribe(subID)
+
+	b.PublishRaw(req)
+
+	return wait(ctx, replyType, matched)
+}
+
+// Reply publishes reply with the correlation ID of req, answering a Request.
+// The Data map of reply is copied, not modified.
+func (b *Bus) Reply(req Event, reply Event) {
+	if id := CorrelationID(req); id != "" {
+		reply.Data = withCorrelationID(reply.Data, id)
+	}
+
+	b.PublishRaw(reply)
+}
+
+// CorrelationID returns the correlation ID carried by e, or "".
+func CorrelationID(e Event) string {
+	id, _ := e.Data[CorrelationIDKey].(string)
+
+	return id
+}
+
+// await subscribes to eventType and returns a channel receiving the first
+// event that satisfies predicate, along with the subscription ID.
+func (b *Bus) await(eventType Type, predicate func(Event) bool) (<-chan Event, string) {
+	matched := make(chan Event, 1)
+
+	id := b.Subscribe(eventType, func(e Event) {
+		if predicate != nil && !predicate(e) {
+			return
+		}
+
+		// Keep only the first match
+		select {
+		case matched <- e:
+		default:
+		}
+	})
+
+	return matched, id
+}
+
+func wait(ctx context.Context, eventType Type, matched <-chan Event) (Event, error) {
+	select {
+	case e := <-matched:
+		return e, nil
+	case <-ctx.Done():
+		return Event{}, fmt.Errorf("waiting for %s: %w", eventType, ctx.Err())
+	}
+}
+
+// withCorrelationID returns a copy of data with the correlation ID set.
+func withCorrelationID(data map[string]any, id string) map[string]any {
+	out := make(map[string]any, len(data)+1)
+	maps.Copy(out, data)
+	out[CorrelationIDKey] = id
+
+	return out
+}
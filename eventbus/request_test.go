@@ -0,0 +1,116 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBus_WaitFor(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	go func() {
+		for !bus.HasSubscribers("agent.finished") {
+			time.Sleep(time.Millisecond)
+		}
+		bus.PublishRaw(Event{Type: "agent.finished", Data: map[string]any{"task_id": "other"}})
+		bus.PublishRaw(Event{Type: "agent.finished", Data: map[string]any{"task_id": "t1"}})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	e, err := bus.WaitFor(ctx, "agent.finished", func(e Event) bool {
+		return e.Data["task_id"] == "t1"
+	})
+	if err != nil {
+		t.Fatalf("WaitFor: %v", err)
+	}
+	if e.Data["task_id"] != "t1" {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+	if bus.HasSubscribers("agent.finished") {
+		t.Fatal("expected WaitFor to unsubscribe")
+	}
+}
+
+func TestBus_WaitForTimeout(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := bus.WaitFor(ctx, "never", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if bus.HasSubscribers("never") {
+		t.Fatal("expected subscription to be removed after timeout")
+	}
+}
+
+func TestBus_RequestReply(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	// Responder answers synchronously, before Request starts waiting
+	bus.Subscribe("sum.request", func(e Event) {
+		a, _ := e.Data["a"].(int)
+		b, _ := e.Data["b"].(int)
+		bus.Reply(e, Event{Type: "sum.reply", Data: map[string]any{"sum": a + b}})
+	})
+
+	// Unrelated replies must not be picked up
+	bus.PublishRaw(Event{Type: "sum.reply", Data: map[string]any{CorrelationIDKey: "stale", "sum": -1}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	data := map[string]any{"a": 2, "b": 3}
+	reply, err := bus.Request(ctx, Event{Type: "sum.request", Data: data}, "sum.reply")
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if reply.Data["sum"] != 5 {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+	if CorrelationID(reply) == "" {
+		t.Fatal("expected reply to carry a correlation ID")
+	}
+	if _, ok := data[CorrelationIDKey]; ok {
+		t.Fatal("expected caller's data not to be modified")
+	}
+	if bus.HasSubscribers("sum.reply") {
+		t.Fatal("expected Request to unsubscribe")
+	}
+}
+
+func TestBus_RequestKeepsCorrelationID(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	bus.Subscribe("ping", func(e Event) {
+		go bus.Reply(e, Event{Type: "pong"})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	reply, err := bus.Request(ctx, Event{Type: "ping", Data: map[string]any{CorrelationIDKey: "req-1"}}, "pong")
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if CorrelationID(reply) != "req-1" {
+		t.Fatalf("expected correlation ID req-1, got %q", CorrelationID(reply))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := bus.Request(ctx, Event{Type: "unanswered"}, "pong"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
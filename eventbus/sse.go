@@ -0,0 +1,323 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-toolkit/log"
+)
+
+const (
+	// DefaultSSEBuffer is the number of recent events kept for Last-Event-ID resume.
+	DefaultSSEBuffer = 256
+	// DefaultSSEHeartbeat is the interval between keep-alive comments.
+	DefaultSSEHeartbeat = 15 * time.Second
+)
+
+// SSEOption configures an SSEHandler.
+type SSEOption func(*sseOptions)
+
+type sseOptions struct {
+	buffer    int
+	heartbeat time.Duration
+}
+
+// WithSSEBuffer sets how many recent events are kept so reconnecting clients
+// can resume from their Last-Event-ID. It also bounds how far a client may
+// fall behind before it is disconnected. Defaults to DefaultSSEBuffer.
+func WithSSEBuffer(n int) SSEOption {
+	return func(o *sseOptions) {
+		if n > 0 {
+			o.buffer = n
+		}
+	}
+}
+
+// WithHeartbeat sets the interval between keep-alive comments, which stop
+// proxies from closing idle streams. Defaults to DefaultSSEHeartbeat.
+func WithHeartbeat(d time.Duration) SSEOption {
+	return func(o *sseOptions) {
+		if d > 0 {
+			o.heartbeat = d
+		}
+	}
+}
+
+// sseEvent is a buffered event with its stream ID.
+type sseEvent struct {
+	event Event
+	id    uint64
+}
+
+// sseClient is a connected stream.
+type sseClient struct {
+	events chan sseEvent
+	filter *patternTrie
+	// gone is closed when the client is removed.
+	gone chan struct{}
+}
+
+func (c *sseClient) wants(eventType Type) bool {
+	return c.filter == nil || c.filter.matches(eventType)
+}
+
+// SSEHandler streams bus events to HTTP clients as Server-Sent Events.
+//
+// Clients choose event types with one or more "type" query parameters, which
+// accept SubscribePattern patterns and may be comma-separated, e.g.
+// "/events?type=task.**,agent.started". Without them all events are sent.
+//
+// Each event is sent with an increasing ID, its type as the SSE event name
+// and a JSON object holding type, data and timestamp. Recent events are kept
+// in a ring buffer, so a client reconnecting with Last-Event-ID (header or
+// "lastEventId" query parameter) receives the events it missed, as far as
+// the buffer reaches. A client that falls more than the buffer size behind
+// is disconnected and can resume the same way.
+type SSEHandler struct {
+	bus       *Bus
+	subID     string
+	heartbeat time.Duration
+
+	mu      sync.Mutex
+	ring    []sseEvent
+	next    int
+	lastID  uint64
+	clients map[*sseClient]struct{}
+	closed  bool
+}
+
+// NewSSEHandler creates a handler streaming events from bus. It subscribes
+// to bus right away to fill the resume buffer; call Close to unsubscribe.
+func NewSSEHandler(bus *Bus, opts ...SSEOption) *SSEHandler {
+	o := &sseOptions{
+		buffer:    DefaultSSEBuffer,
+		heartbeat: DefaultSSEHeartbeat,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	h := &SSEHandler{
+		bus:       bus,
+		heartbeat: o.heartbeat,
+		ring:      make([]sseEvent, 0, o.buffer),
+		clients:   make(map[*sseClient]struct{}),
+	}
+	h.subID = bus.SubscribeAll(h.record)
+
+	return h
+}
+
+// Clients returns the number of connected streams.
+func (h *SSEHandler) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.clients)
+}
+
+// Close unsubscribes from the bus and ends all streams.
+func (h *SSEHandler) Close() {
+	h.bus.Unsubscribe(h.subID)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for client := range h.clients {
+		h.removeLocked(client)
+	}
+}
+
+// record buffers event and hands it to matching clients.
+func (h *SSEHandler) record(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	buffered := sseEvent{event: event, id: h.lastID}
+
+	if len(h.ring) < cap(h.ring) {
+		h.ring = append(h.ring, buffered)
+	} else {
+		h.ring[h.next] = buffered
+		h.next = (h.next + 1) % len(h.ring)
+	}
+
+	for client := range h.clients {
+		if !client.wants(event.Type) {
+			continue
+		}
+
+		select {
+		case client.events <- buffered:
+		default:
+			// Too far behind; the client can resume with Last-Event-ID
+			h.removeLocked(client)
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+
+		return
+	}
+
+	client := &sseClient{
+		events: make(chan sseEvent, cap(h.ring)),
+		filter: parseSSEFilter(r),
+		gone:   make(chan struct{}),
+	}
+
+	backlog, ok := h.register(client, lastEventID(r))
+	if !ok {
+		http.Error(w, "event stream closed", http.StatusServiceUnavailable)
+
+		return
+	}
+	defer h.remove(client)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, buffered := range backlog {
+		if err := writeSSEEvent(w, buffered); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-client.gone:
+			return
+		case buffered := <-client.events:
+			if err := writeSSEEvent(w, buffered); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// register adds client and returns the buffered events after lastID that it
+// wants. Registration and the backlog snapshot happen under one lock, so no
+// event is missed or sent twice.
+func (h *SSEHandler) register(client *sseClient, lastID uint64) ([]sseEvent, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, false
+	}
+	h.clients[client] = struct{}{}
+
+	if lastID == 0 {
+		return nil, true
+	}
+
+	var backlog []sseEvent
+	for i := range h.ring {
+		buffered := h.ring[(h.next+i)%len(h.ring)]
+		if buffered.id > lastID && client.wants(buffered.event.Type) {
+			backlog = append(backlog, buffered)
+		}
+	}
+
+	return backlog, true
+}
+
+func (h *SSEHandler) remove(client *sseClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeLocked(client)
+}
+
+// removeLocked drops client. Must be called with h.mu held.
+func (h *SSEHandler) removeLocked(client *sseClient) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	delete(h.clients, client)
+	close(client.gone)
+}
+
+func writeSSEEvent(w http.ResponseWriter, buffered sseEvent) error {
+	e := buffered.event
+	data, err := json.Marshal(wireEvent{Type: e.Type, Data: e.Data, Timestamp: e.Timestamp})
+	if err != nil {
+		// Skip events whose data cannot be encoded rather than ending the stream
+		log.Warn("eventbus sse skipping unencodable event", "type", e.Type, log.Err(err))
+
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", buffered.id, sanitizeSSEField(string(e.Type)), data)
+
+	return err
+}
+
+// sanitizeSSEField removes line breaks, which would end an SSE field early.
+func sanitizeSSEField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// parseSSEFilter builds a filter from the "type" query parameters, or
+// returns nil if there are none.
+func parseSSEFilter(r *http.Request) *patternTrie {
+	var filter *patternTrie
+	for _, value := range r.URL.Query()["type"] {
+		for pattern := range strings.SplitSeq(value, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if filter == nil {
+				filter = newPatternTrie()
+			}
+			filter.add(pattern, Subscription{ID: pattern})
+		}
+	}
+
+	return filter
+}
+
+// lastEventID reads the resume position from the Last-Event-ID header or
+// the lastEventId query parameter.
+func lastEventID(r *http.Request) uint64 {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
@@ -0,0 +1,209 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseMessage is a parsed Server-Sent Event.
+type sseMessage struct {
+	id    string
+	event string
+	data  string
+}
+
+// openStream connects to url and returns a channel of parsed messages.
+func openStream(t *testing.T, url string, lastID string) (<-chan sseMessage, context.CancelFunc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	messages := make(chan sseMessage, 64)
+	go func() {
+		defer func() { _ = resp.Body.Close() }()
+		defer close(messages)
+
+		var msg sseMessage
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				messages <- msg
+				msg = sseMessage{}
+			case strings.HasPrefix(line, ":"):
+				msg.event = "comment"
+			case strings.HasPrefix(line, "id: "):
+				msg.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				msg.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				msg.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	return messages, cancel
+}
+
+func nextMessage(t *testing.T, messages <-chan sseMessage) sseMessage {
+	t.Helper()
+
+	select {
+	case msg, ok := <-messages:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	return sseMessage{}
+}
+
+func TestSSEHandler_StreamsFilteredEvents(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	handler := NewSSEHandler(bus)
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	messages, cancel := openStream(t, server.URL+"?type=task.*,agent.started", "")
+	defer cancel()
+	waitUntil(t, func() bool { return handler.Clients() == 1 })
+
+	bus.PublishRaw(Event{Type: "other"})
+	bus.PublishRaw(Event{Type: "task.created", Data: map[string]any{"id": "t1"}})
+	bus.PublishRaw(Event{Type: "agent.started"})
+
+	msg := nextMessage(t, messages)
+	if msg.id != "2" || msg.event != "task.created" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+
+	var e wireEvent
+	if err := json.Unmarshal([]byte(msg.data), &e); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if e.Type != "task.created" || e.Data["id"] != "t1" {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	if msg := nextMessage(t, messages); msg.id != "3" || msg.event != "agent.started" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestSSEHandler_ResumeFromLastEventID(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	handler := NewSSEHandler(bus, WithSSEBuffer(3))
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	for range 5 {
+		bus.PublishRaw(Event{Type: "tick"})
+	}
+
+	// Events 1 and 2 have left the ring buffer
+	messages, cancel := openStream(t, server.URL, "1")
+	defer cancel()
+
+	for _, want := range []string{"3", "4", "5"} {
+		if msg := nextMessage(t, messages); msg.id != want {
+			t.Fatalf("expected id %s, got %+v", want, msg)
+		}
+	}
+
+	waitUntil(t, func() bool { return handler.Clients() == 1 })
+	bus.PublishRaw(Event{Type: "tick"})
+	if msg := nextMessage(t, messages); msg.id != "6" {
+		t.Fatalf("expected live event 6, got %+v", msg)
+	}
+}
+
+func TestSSEHandler_HeartbeatAndDisconnect(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	handler := NewSSEHandler(bus, WithHeartbeat(10*time.Millisecond))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	messages, cancel := openStream(t, server.URL, "")
+	if msg := nextMessage(t, messages); msg.event != "comment" {
+		t.Fatalf("expected heartbeat comment, got %+v", msg)
+	}
+
+	cancel()
+	waitUntil(t, func() bool { return handler.Clients() == 0 })
+
+	handler.Close()
+	if bus.HasSubscribers("any") {
+		t.Fatal("expected Close to unsubscribe from the bus")
+	}
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 after Close, got %d", resp.StatusCode)
+	}
+}
+
+func TestSSEHandler_SlowClientDisconnected(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	handler := NewSSEHandler(bus, WithSSEBuffer(2))
+	defer handler.Close()
+
+	client := &sseClient{events: make(chan sseEvent, 2), gone: make(chan struct{})}
+	if _, ok := handler.register(client, 0); !ok {
+		t.Fatal("register failed")
+	}
+
+	for range 3 {
+		bus.PublishRaw(Event{Type: "tick"})
+	}
+
+	select {
+	case <-client.gone:
+	default:
+		t.Fatal("expected slow client to be removed")
+	}
+	if handler.Clients() != 0 {
+		t.Fatalf("expected no clients, got %d", handler.Clients())
+	}
+}
@@ -0,0 +1,96 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TypedEvent is an event carrying a concrete payload of type T.
+// It implements Eventer, so it can be published with Bus.Publish.
+type TypedEvent[T any] struct {
+	Timestamp time.Time
+	Payload   T
+	Type      Type
+}
+
+// ToEvent converts the typed event to an Event. Payload is kept as-is in
+// Event.Payload and also encoded into Data through JSON, so untyped
+// subscribers keep working. T should encode to a JSON object; other
+// payloads leave Data nil.
+func (e TypedEvent[T]) ToEvent() Event {
+	ts := e.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	return Event{
+		Type:      e.Type,
+		Timestamp: ts,
+		Data:      toData(e.Payload),
+		Payload:   e.Payload,
+	}
+}
+
+// PublishTyped publishes payload as an event of the given type.
+func PublishTyped[T any](b *Bus, eventType Type, payload T) {
+	b.Publish(TypedEvent[T]{Type: eventType, Payload: payload})
+}
+
+// SubscribeTyped registers a handler for eventType that receives the
+// payload decoded as T. Events whose payload cannot be decoded as T are not
+// passed to handler; the decode error is reported like a handler error,
+// through the error hook and in PublishResult (see SubscribeE).
+// Returns subscription ID for later unsubscription.
+func SubscribeTyped[T any](b *Bus, eventType Type, handler func(TypedEvent[T]), opts ...SubscribeOption) string {
+	return b.SubscribeE(eventType, func(e Event) error {
+		payload, err := PayloadAs[T](e)
+		if err != nil {
+			return err
+		}
+
+		handler(TypedEvent[T]{Type: e.Type, Timestamp: e.Timestamp, Payload: payload})
+
+		return nil
+	}, opts...)
+}
+
+// PayloadAs returns the payload of e as T. If e.Payload does not hold a T,
+// for example because the event was published untyped or crossed a JSON
+// boundary, Data is decoded into T instead.
+func PayloadAs[T any](e Event) (T, error) {
+	if payload, ok := e.Payload.(T); ok {
+		return payload, nil
+	}
+
+	var payload T
+	if e.Data == nil {
+		return payload, fmt.Errorf("event %s has no payload", e.Type)
+	}
+
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return payload, fmt.Errorf("encode event %s data: %w", e.Type, err)
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return payload, fmt.Errorf("decode event %s data as %T: %w", e.Type, payload, err)
+	}
+
+	return payload, nil
+}
+
+// toData encodes v into a JSON object map. Returns nil if v does not
+// encode to a JSON object.
+func toData(v any) map[string]any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil
+	}
+
+	return data
+}
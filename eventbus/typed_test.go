@@ -0,0 +1,120 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type taskCreated struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Steps int    `json:"steps"`
+}
+
+func TestSubscribeTyped(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	var got TypedEvent[taskCreated]
+	SubscribeTyped(bus, "task.created", func(e TypedEvent[taskCreated]) {
+		got = e
+	})
+
+	var raw Event
+	bus.Subscribe("task.created", func(e Event) {
+		raw = e
+	})
+
+	want := taskCreated{ID: "t1", Title: "Write docs", Steps: 3}
+	PublishTyped(bus, "task.created", want)
+
+	if got.Payload != want {
+		t.Fatalf("expected payload %+v, got %+v", want, got.Payload)
+	}
+	if got.Timestamp.IsZero() {
+		t.Fatal("expected timestamp to be set")
+	}
+
+	// Untyped subscribers still see the payload through Data
+	if raw.Data["title"] != "Write docs" || raw.Data["steps"] != float64(3) {
+		t.Fatalf("unexpected data: %v", raw.Data)
+	}
+}
+
+func TestSubscribeTyped_FromData(t *testing.T) {
+	bus := NewBus()
+	defer bus.Shutdown()
+
+	var got taskCreated
+	SubscribeTyped(bus, "task.created", func(e TypedEvent[taskCreated]) {
+		got = e.Payload
+	})
+
+	bus.PublishRaw(Event{
+		Type: "task.created",
+		Data: map[string]any{"id": "t2", "title": "Review", "steps": 1},
+	})
+
+	if got != (taskCreated{ID: "t2", Title: "Review", Steps: 1}) {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
+
+func TestSubscribeTyped_ReportsUndecodable(t *testing.T) {
+	var reported []*DeliveryError
+	bus := NewBus(WithErrorHook(func(err *DeliveryError) {
+		reported = append(reported, err)
+	}))
+	defer bus.Shutdown()
+
+	called := false
+	id := SubscribeTyped(bus, "task.created", func(e TypedEvent[taskCreated]) {
+		called = true
+	})
+
+	result := bus.PublishRawCollect(Event{Type: "task.created", Data: map[string]any{"steps": "many"}})
+	bus.PublishRaw(Event{Type: "task.created"})
+
+	if called {
+		t.Fatal("expected undecodable events not to reach the handler")
+	}
+	if len(result.Failed) != 1 || result.Failed[0].SubscriptionID != id {
+		t.Fatalf("expected the decode failure in PublishResult, got %+v", result)
+	}
+	if len(reported) != 2 {
+		t.Fatalf("expected both decode failures to reach the error hook, got %d", len(reported))
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(reported[0].Err, &typeErr) {
+		t.Fatalf("expected the JSON decode error, got %v", reported[0].Err)
+	}
+}
+
+func TestPayloadAs_JSONRoundTrip(t *testing.T) {
+	event := TypedEvent[taskCreated]{
+		Type:    "task.created",
+		Payload: taskCreated{ID: "t3", Title: "Ship", Steps: 2},
+	}.ToEvent()
+
+	raw, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var decoded Event
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Payload != nil {
+		t.Fatalf("expected Payload not to be serialized, got %v", decoded.Payload)
+	}
+
+	payload, err := PayloadAs[taskCreated](decoded)
+	if err != nil {
+		t.Fatalf("PayloadAs: %v", err)
+	}
+	if payload.Title != "Ship" || payload.Steps != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
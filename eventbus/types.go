@@ -9,6 +9,15 @@
 //
 // Features:
 //   - Type-based event routing with wildcard support
+//   - Pattern subscriptions over dotted types ("task.*", "task.**")
+//   - Generic typed payloads (SubscribeTyped, PublishTyped)
+//   - Per-handler panic recovery and error-returning handlers
+//   - Ordered per-subscriber queues with overflow policies
+//   - Optional JSON lines journal with replay
+//   - Cross-process bridge over Unix domain sockets
+//   - Server-Sent Events handler with Last-Event-ID resume
+//   - WaitFor and request/reply with correlation IDs
+//   - Publish and delivery middleware
 //   - Synchronous and asynchronous publishing
 //   - Semaphore-based limiting for async operations
 //   - Graceful shutdown with context cancellation
@@ -32,7 +41,13 @@ type Type string
 type Event struct {
 	Timestamp time.Time
 	Data      map[string]any
-	Type      Type
+	// Payload optionally carries the concrete value behind Data for typed
+	// events. It is not serialized; consumers fall back to decoding Data.
+	Payload any `json:"-"`
+	Type    Type
+	// Seq is the journal sequence number of the event, set when the bus
+	// journals it; 0 if the bus has no journal or appending failed.
+	Seq uint64
 }
 
 // Eventer interface for typed events.
@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"github.com/valksor/go-toolkit/retry"
+)
+
+// breakerTransport is an http.RoundTripper guarded by a circuit breaker.
+type breakerTransport struct {
+	breaker *retry.Breaker
+	next    http.RoundTripper
+}
+
+// NewBreakerTransport wraps next so that requests go through breaker.
+// Transport errors and responses with a status code ShouldRetry considers
+// retryable (429, 502, 503, 504) are reported to the breaker, which by
+// default counts them as failures if retry.DefaultClassifier considers them
+// transient, as it does connection errors and timeouts. Requests cancelled
+// by their context count neither way. The response itself is still returned
+// to the caller. While the breaker is open, requests fail
+// immediately with a *retry.CircuitOpenError, which matches
+// retry.ErrCircuitOpen. If next is nil, http.DefaultTransport is used.
+func NewBreakerTransport(breaker *retry.Breaker, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &breakerTransport{breaker: breaker, next: next}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		done(err)
+	case ShouldRetry(NewHTTPError(resp.StatusCode, "")):
+		done(NewHTTPError(resp.StatusCode, resp.Status))
+	default:
+		done(nil)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valksor/go-toolkit/retry"
+)
+
+func TestBreakerTransport(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	breaker := retry.NewBreaker(retry.BreakerConfig{Name: "api", MinRequests: 2})
+	client := &http.Client{Transport: NewBreakerTransport(breaker, nil)}
+
+	for range 2 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("expected response while closed, got %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503 to be passed through, got %d", resp.StatusCode)
+		}
+	}
+
+	if breaker.State() != retry.StateOpen {
+		t.Fatalf("expected breaker to open after 503s, got %v", breaker.State())
+	}
+
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, retry.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected no request while open, got %d calls", calls)
+	}
+}
+
+func TestBreakerTransport_ClientErrorsAreSuccesses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	breaker := retry.NewBreaker(retry.BreakerConfig{MinRequests: 1})
+	client := &http.Client{Transport: NewBreakerTransport(breaker, nil)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if breaker.State() != retry.StateClosed {
+		t.Fatal("expected 404 not to count as a failure")
+	}
+}
+
+func TestBreakerTransport_ConnectionErrorsAreFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	breaker := retry.NewBreaker(retry.BreakerConfig{MinRequests: 1})
+	client := &http.Client{Transport: NewBreakerTransport(breaker, nil)}
+
+	if _, err := client.Get(url); err == nil {
+		t.Fatal("expected connection error")
+	}
+	if breaker.State() != retry.StateOpen {
+		t.Fatal("expected a refused connection to count as a failure")
+	}
+}
+
+func TestBreakerTransport_CanceledRequestIgnored(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	breaker := retry.NewBreaker(retry.BreakerConfig{MinRequests: 1})
+	client := &http.Client{Transport: NewBreakerTransport(breaker, nil)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled request, got %v", err)
+	}
+
+	if breaker.State() != retry.StateClosed {
+		t.Fatal("expected a canceled request not to count as a failure")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	providererrors "github.com/valksor/go-toolkit/errors"
+	"github.com/valksor/go-toolkit/retry"
 )
 
 // Default configuration values used across providers.
@@ -47,10 +48,13 @@ func defaultTransport() *http.Transport {
 }
 
 // HTTPError represents an HTTP error with status code.
-// This type implements the HTTPStatusCode() interface expected by providererrors.
+// This type implements the HTTPStatusCode() interface expected by providererrors,
+// and retry.RetryAfterer when the server specified a delay.
 type HTTPError struct {
 	Message string
 	Code    int
+	// Delay is the server-specified wait before retrying, or 0.
+	Delay time.Duration
 }
 
 func (e *HTTPError) Error() string {
@@ -66,17 +70,33 @@ func (e *HTTPError) HTTPStatusCode() int {
 	return e.Code
 }
 
+// RetryAfter returns the server-specified delay before retrying, or 0.
+func (e *HTTPError) RetryAfter() time.Duration {
+	return e.Delay
+}
+
 // NewHTTPError creates a new HTTPError with the given code and message.
 func NewHTTPError(code int, message string) *HTTPError {
 	return &HTTPError{Code: code, Message: message}
 }
 
 // RetryConfig controls retry behavior.
+// It is a thin layer over retry.Config; see Config for the mapping.
 type RetryConfig struct {
 	MaxRetries     int
 	InitialBackoff time.Duration
 	MaxBackoff     time.Duration
 	Multiplier     float64
+	// Jitter adds ±25% randomness to each backoff.
+	Jitter bool
+	// IsRetryable classifies errors; defaults to ShouldRetry.
+	IsRetryable func(error) bool
+	// OnRetry is called before waiting to retry (optional).
+	OnRetry retry.OnRetryFunc
+	// RetryNonIdempotent lets the Retry middleware retry requests whose
+	// method is not idempotent, such as POST, even without an
+	// Idempotency-Key header.
+	RetryNonIdempotent bool
 }
 
 // DefaultRetryConfig returns the default retry configuration.
@@ -86,16 +106,45 @@ func DefaultRetryConfig() RetryConfig {
 		InitialBackoff: DefaultBackoff,
 		MaxBackoff:     MaxBackoff,
 		Multiplier:     BackoffMultiplier,
+		Jitter:         true,
+	}
+}
+
+// Config converts c to a retry.Config. MaxRetries counts retries, so the
+// resulting MaxAttempts is MaxRetries+1. Set retry.Config fields such as
+// Breaker or Budget on the result and use it directly for more control.
+func (c RetryConfig) Config() retry.Config {
+	isRetryable := c.IsRetryable
+	if isRetryable == nil {
+		isRetryable = ShouldRetry
+	}
+
+	return retry.Config{
+		MaxAttempts:     c.MaxRetries + 1,
+		BaseDelay:       c.InitialBackoff,
+		MaxDelay:        c.MaxBackoff,
+		ExponentialBase: c.Multiplier,
+		Jitter:          c.Jitter,
+		IsRetryableFunc: isRetryable,
+		OnRetry:         c.OnRetry,
 	}
 }
 
 // ShouldRetry determines if an error is retryable.
-// Returns true for rate limiting (429), service unavailable (503), and network errors.
+// Returns true for rate limiting (429), service unavailable (503), network
+// errors, and errors carrying a server-specified retry delay. Errors without
+// a status code, such as those returned by http.Client and transports, are
+// classified by retry.DefaultClassifier: refused connections and timeouts
+// are retried.
 func ShouldRetry(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	if _, ok := retry.RetryAfter(err); ok {
+		return true
+	}
+
 	// Check for wrapped provider errors
 	if errors.Is(err, providererrors.ErrRateLimited) {
 		return true
@@ -115,55 +164,43 @@ func ShouldRetry(err error) bool {
 			code == http.StatusBadGateway
 	}
 
-	return false
+	// Transport errors (*url.Error wrapping *net.OpError, timeouts, ...)
+	return retry.DefaultClassifier(err)
 }
 
 // RetryFunc is a function that performs an operation that may need retrying.
 type RetryFunc func() error
 
 // WithRetry executes the given function with exponential backoff retry.
-// It respects context cancellation and stops when the context is done.
+// It is WithRetryContext for functions that do not take a context.
 func WithRetry(ctx context.Context, config RetryConfig, fn RetryFunc) error {
-	var lastErr error
-	backoff := config.InitialBackoff
-
-	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
-		err := fn()
-		if err == nil {
-			return nil
-		}
-
-		lastErr = err
-
-		// Check if error is retryable
-		if !ShouldRetry(err) {
-			return err
-		}
-
-		// Don't wait after last attempt
-		if attempt == config.MaxRetries {
-			break
-		}
+	return WithRetryContext(ctx, config, func(context.Context) error {
+		return fn()
+	})
+}
 
-		// Wait with exponential backoff
-		select {
-		case <-time.After(backoff):
-			backoff = time.Duration(float64(backoff) * config.Multiplier)
-			if backoff > config.MaxBackoff {
-				backoff = config.MaxBackoff
-			}
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+// WithRetryContext executes fn with exponential backoff retry, passing it
+// ctx. If an error carries a server-specified delay (see
+// NewHTTPErrorFromResponse), that delay is used instead of the backoff,
+// capped at MaxBackoff. It stops when the context is done.
+//
+// When every attempt fails, the last error is returned as is, so callers can
+// still compare or type-assert it.
+func WithRetryContext(ctx context.Context, config RetryConfig, fn func(context.Context) error) error {
+	result := config.Config().DoWithResult(ctx, fn)
+	if result.Outcome == retry.OutcomeAttemptsExhausted {
+		return errors.Unwrap(result.Err)
 	}
 
-	return lastErr
+	return result.Err
 }
 
 // NewHTTPClient creates a shared http.Client with connection pooling.
 // The same client instance is reused across all providers, enabling
 // TCP connection and TLS session reuse for 20-30% performance improvement.
-func NewHTTPClient() *http.Client {
+// With middleware, a new client is returned whose transport wraps the
+// shared one (see NewTransport), so connections are still shared.
+func NewHTTPClient(mw ...Middleware) *http.Client {
 	sharedClientOnce.Do(func() {
 		sharedClient = &http.Client{
 			Timeout:   DefaultTimeout,
@@ -171,15 +208,23 @@ func NewHTTPClient() *http.Client {
 		}
 	})
 
-	return sharedClient
+	if len(mw) == 0 {
+		return sharedClient
+	}
+
+	return &http.Client{
+		Timeout:   DefaultTimeout,
+		Transport: NewTransport(sharedClient.Transport, mw...),
+	}
 }
 
 // NewHTTPClientWithTimeout creates a new http.Client with a custom timeout.
 // Unlike NewHTTPClient(), this returns a fresh client instance since the timeout
 // differs from the default. The returned client still uses connection pooling.
-func NewHTTPClientWithTimeout(timeout time.Duration) *http.Client {
+// Its transport is wrapped with mw as by NewTransport.
+func NewHTTPClientWithTimeout(timeout time.Duration, mw ...Middleware) *http.Client {
 	return &http.Client{
 		Timeout:   timeout,
-		Transport: defaultTransport(),
+		Transport: NewTransport(defaultTransport(), mw...),
 	}
 }
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryConfig_Config(t *testing.T) {
+	config := DefaultRetryConfig().Config()
+
+	if config.MaxAttempts != DefaultMaxRetries+1 {
+		t.Errorf("expected %d attempts, got %d", DefaultMaxRetries+1, config.MaxAttempts)
+	}
+	if config.BaseDelay != DefaultBackoff || config.MaxDelay != MaxBackoff {
+		t.Errorf("unexpected delays: %v, %v", config.BaseDelay, config.MaxDelay)
+	}
+	if !config.Jitter {
+		t.Error("expected jitter by default")
+	}
+	if !config.IsRetryable(NewHTTPError(http.StatusTooManyRequests, "")) {
+		t.Error("expected ShouldRetry as default classifier")
+	}
+	if config.IsRetryable(NewHTTPError(http.StatusBadRequest, "")) {
+		t.Error("expected 400 not to be retryable")
+	}
+}
+
+func TestWithRetryContext(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	var retries []int
+	config := RetryConfig{
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Multiplier:     2,
+		IsRetryable:    func(err error) bool { return errors.Is(err, errCustom) },
+		OnRetry: func(attempt int, _ error, _ time.Duration) {
+			retries = append(retries, attempt)
+		},
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	attempts := 0
+	err := WithRetryContext(ctx, config, func(ctx context.Context) error {
+		if ctx.Value(ctxKey{}) != "value" {
+			t.Error("expected caller's context to be passed to fn")
+		}
+		attempts++
+
+		return errCustom
+	})
+
+	if err != errCustom { //nolint:errorlint // the last error must be returned unwrapped
+		t.Fatalf("expected last error to be returned as is, got %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Fatalf("unexpected OnRetry attempts: %v", retries)
+	}
+}
+
+func TestWithRetry_NonRetryable(t *testing.T) {
+	attempts := 0
+	err := WithRetry(context.Background(), DefaultRetryConfig(), func() error {
+		attempts++
+
+		return NewHTTPError(http.StatusNotFound, "missing")
+	})
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
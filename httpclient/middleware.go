@@ -0,0 +1,394 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-toolkit/log"
+	"github.com/valksor/go-toolkit/providerconfig"
+	"github.com/valksor/go-toolkit/retry"
+	"github.com/valksor/go-toolkit/version"
+)
+
+// Config keys read by AuthFromConfig.
+const (
+	ConfigKeyAuthType = "auth_type"
+	ConfigKeyToken    = "token"
+	ConfigKeyUsername = "username"
+)
+
+// Auth types for ConfigKeyAuthType.
+const (
+	AuthTypeBearer = "bearer"
+	AuthTypeBasic  = "basic"
+	AuthTypeToken  = "token"
+)
+
+// RedactedValue replaces secrets in debug logs.
+const RedactedValue = "[REDACTED]"
+
+// HeaderIdempotencyKey marks a request as safe to retry whatever its method.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// Middleware wraps an http.RoundTripper with additional behavior.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// NewTransport wraps base with mw, the first middleware being the outermost.
+// If base is nil, the shared transport from NewHTTPClient is used.
+//
+// A typical stack sets headers first, logs what is sent, and retries with
+// a timeout per attempt:
+//
+//	transport := httpclient.NewTransport(nil,
+//	    httpclient.UserAgent("mehr"),
+//	    httpclient.BearerAuth(token),
+//	    httpclient.DebugLogging(),
+//	    httpclient.Retry(httpclient.DefaultRetryConfig()),
+//	    httpclient.Timeout(10*time.Second),
+//	)
+func NewTransport(base http.RoundTripper, mw ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = NewHTTPClient().Transport
+	}
+
+	rt := base
+	for _, m := range slices.Backward(mw) {
+		rt = m(rt)
+	}
+
+	return rt
+}
+
+// setHeader returns a middleware setting header to value on requests that
+// do not already have it. Requests are cloned rather than modified.
+func setHeader(header, value string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) != "" {
+				return next.RoundTrip(req)
+			}
+
+			req = req.Clone(req.Context())
+			req.Header.Set(header, value)
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// BearerAuth sets "Authorization: Bearer <token>" on requests without an
+// Authorization header.
+func BearerAuth(token string) Middleware {
+	return setHeader("Authorization", "Bearer "+token)
+}
+
+// BasicAuth sets HTTP basic authentication on requests without an
+// Authorization header.
+func BasicAuth(username, password string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "" {
+				return next.RoundTrip(req)
+			}
+
+			req = req.Clone(req.Context())
+			req.SetBasicAuth(username, password)
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// TokenAuth sets "Authorization: token <token>", as GitHub accepts, on
+// requests without an Authorization header.
+func TokenAuth(token string) Middleware {
+	return setHeader("Authorization", "token "+token)
+}
+
+// AuthFromConfig authenticates requests with the credentials in cfg.
+// ConfigKeyAuthType selects the scheme: AuthTypeBearer (default),
+// AuthTypeBasic with ConfigKeyUsername and the token as password, or
+// AuthTypeToken. If cfg has no token, or only an unexpanded "${VAR}"
+// reference, requests are passed through unchanged.
+func AuthFromConfig(cfg providerconfig.Config) Middleware {
+	token := cfg.GetString(ConfigKeyToken)
+	if token == "" || strings.HasPrefix(token, "${") {
+		return func(next http.RoundTripper) http.RoundTripper { return next }
+	}
+
+	switch strings.ToLower(cfg.GetString(ConfigKeyAuthType)) {
+	case AuthTypeBasic:
+		return BasicAuth(cfg.GetString(ConfigKeyUsername), token)
+	case AuthTypeToken:
+		return TokenAuth(token)
+	default:
+		return BearerAuth(token)
+	}
+}
+
+// UserAgent sets "User-Agent: <product>/<version>" on requests without a
+// User-Agent header, using version.Short().
+func UserAgent(product string) Middleware {
+	return setHeader("User-Agent", product+"/"+version.Short())
+}
+
+// sensitiveHeaders are always redacted by DebugLogging.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// sensitiveWords mark header and query parameter names as secret.
+var sensitiveWords = []string{"token", "secret", "password", "key", "signature", "session"}
+
+// isSensitive reports whether a header or query parameter called name
+// likely holds a secret.
+func isSensitive(name string) bool {
+	if slices.ContainsFunc(sensitiveHeaders, func(h string) bool { return strings.EqualFold(h, name) }) {
+		return true
+	}
+
+	name = strings.ToLower(name)
+
+	return slices.ContainsFunc(sensitiveWords, func(w string) bool { return strings.Contains(name, w) })
+}
+
+// redactHeader returns a copy of h with secret values replaced by
+// RedactedValue.
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for name := range out {
+		if isSensitive(name) {
+			out[name] = []string{RedactedValue}
+		}
+	}
+
+	return out
+}
+
+// redactURL returns u as a string with secret query parameters and user
+// info replaced by RedactedValue.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User(RedactedValue)
+	}
+
+	query := redacted.Query()
+	changed := false
+	for name := range query {
+		if isSensitive(name) {
+			query[name] = []string{RedactedValue}
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+
+	return redacted.String()
+}
+
+// DebugLogging logs every request and response at debug level through the
+// log package: method, URL, headers, status and duration. Authorization and
+// cookie headers, user info, and headers or query parameters whose names
+// suggest secrets (token, key, password, ...) are replaced by RedactedValue.
+// Bodies are not logged. Nothing is done unless debug logging is enabled.
+func DebugLogging() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			if !log.Logger().Enabled(ctx, slog.LevelDebug) {
+				return next.RoundTrip(req)
+			}
+
+			log.DebugContext(ctx, "http request",
+				"method", req.Method,
+				"url", redactURL(req.URL),
+				"headers", redactHeader(req.Header))
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			duration := time.Since(start)
+
+			if err != nil {
+				log.DebugContext(ctx, "http request failed",
+					"method", req.Method,
+					"url", redactURL(req.URL),
+					"duration", duration,
+					log.Err(err))
+
+				return resp, err
+			}
+
+			log.DebugContext(ctx, "http response",
+				"method", req.Method,
+				"url", redactURL(req.URL),
+				"status", resp.StatusCode,
+				"duration", duration,
+				"headers", redactHeader(resp.Header))
+
+			return resp, nil
+		})
+	}
+}
+
+// Retry retries requests as configured, like WithRetryContext. Transport
+// errors and error responses are retried if config classifies them as
+// retryable; by default ShouldRetry accepts 429, 502, 503 and 504, and
+// Retry-After is honored. When retries run out, the last response is
+// returned as is. Requests with a body are only retried if req.GetBody is
+// set, as it is for requests created by http.NewRequest with common body
+// types.
+//
+// Only idempotent requests are retried: GET, HEAD, OPTIONS, TRACE, PUT and
+// DELETE, or any method with an Idempotency-Key header. Set
+// config.RetryNonIdempotent to retry other methods as well.
+func Retry(config RetryConfig) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+				return next.RoundTrip(req)
+			}
+			if !config.RetryNonIdempotent && !isIdempotent(req) {
+				return next.RoundTrip(req)
+			}
+
+			cfg := config.Config()
+
+			var (
+				last    *http.Response
+				attempt int
+			)
+			err := cfg.DoWithContext(req.Context(), func(ctx context.Context) error {
+				if last != nil {
+					drainAndClose(last.Body)
+					last = nil
+				}
+
+				attemptReq := req
+				if attempt > 0 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return retry.Permanent(err)
+					}
+					attemptReq = req.Clone(ctx)
+					attemptReq.Body = body
+				}
+				attempt++
+
+				resp, err := next.RoundTrip(attemptReq)
+				if err != nil {
+					return err
+				}
+
+				last = resp
+				if resp.StatusCode < http.StatusBadRequest {
+					return nil
+				}
+
+				httpErr := NewHTTPErrorFromResponse(resp)
+				if !cfg.IsRetryable(httpErr) {
+					return nil
+				}
+
+				return httpErr
+			})
+
+			var httpErr *HTTPError
+			if last != nil && (err == nil || errors.As(err, &httpErr)) {
+				return last, nil
+			}
+			if last != nil {
+				drainAndClose(last.Body)
+			}
+
+			return nil, err
+		})
+	}
+}
+
+// isIdempotent reports whether req may be sent more than once: its method
+// is idempotent or it carries an Idempotency-Key header.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(HeaderIdempotencyKey) != ""
+	}
+}
+
+// drainAndClose reads a little of body so the connection can be reused,
+// then closes it.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, 4096)
+	_ = body.Close()
+}
+
+// Timeout limits each request passing through it to d, including reading
+// the response body. Placed after Retry, it limits every attempt.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				cancel()
+
+				return nil, err
+			}
+
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+			return resp, nil
+		})
+	}
+}
+
+// cancelOnClose cancels a request context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+// CircuitBreaker guards requests with breaker; see NewBreakerTransport.
+func CircuitBreaker(breaker *retry.Breaker) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return NewBreakerTransport(breaker, next)
+	}
+}
+
+// RateLimit limits requests per host; see NewRateLimitTransport.
+func RateLimit(opts ...RateLimitOption) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return NewRateLimitTransport(next, opts...)
+	}
+}
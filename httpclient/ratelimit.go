@@ -0,0 +1,326 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how often requests are made.
+// Tokens are added at a steady rate up to a burst size, and every request
+// takes one. It is safe for concurrent use.
+type RateLimiter struct {
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+
+	mu sync.Mutex
+	// base is the configured rate; rate may be lowered from it by Adapt.
+	base   float64
+	rate   float64
+	burst  float64
+	tokens float64
+	// last is when tokens was last refilled. It is in the future while the
+	// limiter is paused until a quota reset.
+	last time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rate requests per second on
+// average and up to burst requests at once. A non-positive rate means no
+// limit; burst is at least 1.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	burst = max(burst, 1)
+
+	return &RateLimiter{
+		now:    time.Now,
+		base:   rate,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be made or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+
+		return ctx.Err()
+	}
+}
+
+// Allow reports whether a request may be made now, taking a token if so.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+
+	if now.Before(l.last) {
+		return false
+	}
+	if l.rate <= 0 {
+		return true
+	}
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+
+	return true
+}
+
+// Rate returns the current rate in requests per second, which Adapt may
+// have lowered from the configured one.
+func (l *RateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
+
+// SetRate changes the configured rate in requests per second.
+func (l *RateLimiter) SetRate(rate float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(l.now())
+	l.base = rate
+	l.rate = rate
+}
+
+// Adapt adjusts the limiter to a quota reported by the server: remaining
+// requests until reset. The rate is lowered so that the remaining requests
+// are spread until the reset, but never raised above the configured rate.
+// With no requests remaining, the limiter pauses until reset. Resets in
+// the past are ignored.
+func (l *RateLimiter) Adapt(remaining int, reset time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	until := reset.Sub(now)
+	if until <= 0 {
+		return
+	}
+
+	l.refill(now)
+
+	if remaining <= 0 {
+		l.tokens = min(l.tokens, 0)
+		if reset.After(l.last) {
+			l.last = reset
+		}
+
+		return
+	}
+
+	quotaRate := float64(remaining) / until.Seconds()
+	if l.base <= 0 || quotaRate < l.base {
+		l.rate = quotaRate
+	} else {
+		l.rate = l.base
+	}
+	l.tokens = min(l.tokens, float64(remaining))
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+
+	paused := max(l.last.Sub(now), 0)
+	if l.rate <= 0 {
+		return paused
+	}
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return paused
+	}
+
+	return paused + time.Duration(-l.tokens/l.rate*float64(time.Second))
+}
+
+// cancel returns a token taken by a Wait that gave up.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate > 0 {
+		l.tokens = min(l.tokens+1, l.burst)
+	}
+}
+
+// refill adds the tokens accumulated since the last refill.
+// Must be called with l.mu held.
+func (l *RateLimiter) refill(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+
+	if l.rate > 0 {
+		l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.burst)
+	}
+	l.last = now
+}
+
+// RateLimitOption configures a rate limiting transport.
+type RateLimitOption func(*rateLimitOptions)
+
+type rateLimitOptions struct {
+	hosts    map[string]*RateLimiter
+	rate     float64
+	burst    int
+	adaptive bool
+}
+
+// WithRateLimit limits requests to hosts without a WithHostRateLimit limit.
+// Each host gets its own limiter. Without it, such hosts are not limited.
+func WithRateLimit(rate float64, burst int) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.rate = rate
+		o.burst = burst
+	}
+}
+
+// WithHostRateLimit limits requests to host (without port, e.g.
+// "api.github.com") to rate per second with the given burst.
+func WithHostRateLimit(host string, rate float64, burst int) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.hosts[strings.ToLower(host)] = NewRateLimiter(rate, burst)
+	}
+}
+
+// WithAdaptiveRateLimit adapts each host's limiter to the quota reported in
+// X-RateLimit-Remaining and X-RateLimit-Reset response headers, and pauses
+// it for the Retry-After delay of 429 responses. Hosts without a configured
+// limit get an unlimited limiter that is only slowed down by the headers.
+func WithAdaptiveRateLimit() RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.adaptive = true
+	}
+}
+
+// rateLimitTransport is an http.RoundTripper limiting requests per host.
+type rateLimitTransport struct {
+	next     http.RoundTripper
+	adaptive bool
+	rate     float64
+	burst    int
+
+	mu    sync.Mutex
+	hosts map[string]*RateLimiter
+}
+
+// NewRateLimitTransport wraps next so that requests wait for their host's
+// rate limiter, giving up when the request context is done. If next is
+// nil, the shared transport from NewHTTPClient is used, so connections are
+// still pooled with other clients.
+func NewRateLimitTransport(next http.RoundTripper, opts ...RateLimitOption) http.RoundTripper {
+	o := &rateLimitOptions{hosts: make(map[string]*RateLimiter)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if next == nil {
+		next = NewHTTPClient().Transport
+	}
+
+	return &rateLimitTransport{
+		next:     next,
+		adaptive: o.adaptive,
+		rate:     o.rate,
+		burst:    o.burst,
+		hosts:    o.hosts,
+	}
+}
+
+// NewRateLimitedClient creates an http.Client with DefaultTimeout whose
+// requests are limited as configured by opts. It shares the connection
+// pool of NewHTTPClient.
+func NewRateLimitedClient(opts ...RateLimitOption) *http.Client {
+	return &http.Client{
+		Timeout:   DefaultTimeout,
+		Transport: NewRateLimitTransport(nil, opts...),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := t.limiter(req.URL.Hostname())
+	if limiter == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	if err := limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err == nil && t.adaptive {
+		adaptToResponse(limiter, resp)
+	}
+
+	return resp, err
+}
+
+// limiter returns the limiter for host, creating it if a default limit or
+// adaptive limiting is configured. Returns nil if host is not limited.
+func (t *rateLimitTransport) limiter(host string) *RateLimiter {
+	host = strings.ToLower(host)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if l, ok := t.hosts[host]; ok {
+		return l
+	}
+	if t.rate <= 0 && !t.adaptive {
+		return nil
+	}
+
+	l := NewRateLimiter(t.rate, t.burst)
+	t.hosts[host] = l
+
+	return l
+}
+
+// adaptToResponse updates limiter from the rate limit headers of resp.
+func adaptToResponse(limiter *RateLimiter, resp *http.Response) {
+	now := limiter.now()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if delay, ok := ParseRetryAfter(resp.Header, now); ok {
+			limiter.Adapt(0, now.Add(delay))
+
+			return
+		}
+	}
+
+	remaining, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get(HeaderRateLimitRemaining)))
+	if err != nil {
+		return
+	}
+	if reset, ok := parseRateLimitReset(resp.Header); ok {
+		limiter.Adapt(remaining, reset)
+	}
+}
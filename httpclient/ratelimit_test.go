@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestLimiter returns a limiter driven by the returned clock.
+func newTestLimiter(rate float64, burst int) (*RateLimiter, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(rate, burst)
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	return l, &now
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	l, now := newTestLimiter(2, 2)
+
+	if !l.Allow() || !l.Allow() {
+		t.Fatal("expected burst of 2 to be allowed")
+	}
+	if l.Allow() {
+		t.Fatal("expected third request to be refused")
+	}
+
+	*now = now.Add(500 * time.Millisecond)
+	if !l.Allow() {
+		t.Fatal("expected a token after 500ms at 2/s")
+	}
+	if l.Allow() {
+		t.Fatal("expected only one token to be refilled")
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	l, _ := newTestLimiter(0, 1)
+
+	for range 100 {
+		if !l.Allow() {
+			t.Fatal("expected unlimited limiter to allow every request")
+		}
+	}
+}
+
+func TestRateLimiter_Adapt(t *testing.T) {
+	l, now := newTestLimiter(10, 5)
+
+	// 30 requests left for the next minute: spread them out
+	l.Adapt(30, now.Add(time.Minute))
+	if got := l.Rate(); got != 0.5 {
+		t.Fatalf("expected rate 0.5/s, got %v", got)
+	}
+
+	// Plenty of quota: back to the configured rate
+	l.Adapt(5000, now.Add(time.Minute))
+	if got := l.Rate(); got != 10 {
+		t.Fatalf("expected configured rate, got %v", got)
+	}
+
+	// Quota exhausted: pause until reset
+	l.Adapt(0, now.Add(time.Second))
+	if l.Allow() {
+		t.Fatal("expected limiter to pause until reset")
+	}
+	*now = now.Add(2 * time.Second)
+	if !l.Allow() {
+		t.Fatal("expected requests after reset")
+	}
+
+	// Resets in the past are ignored
+	l.Adapt(1, now.Add(-time.Second))
+	if got := l.Rate(); got != 10 {
+		t.Fatalf("expected stale reset to be ignored, got rate %v", got)
+	}
+}
+
+func TestRateLimiter_WaitContext(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("expected first token immediately, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRateLimitTransport(nil, WithHostRateLimit("127.0.0.1", 20, 1))}
+
+	start := time.Now()
+	for range 3 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// One request from the burst, then two at 50ms intervals
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected requests to be spaced out, took %v", elapsed)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRateLimitTransport_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRateLimitTransport(nil, WithRateLimit(0.001, 1))}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded while waiting, got %v", err)
+	}
+}
+
+func TestRateLimitTransport_Adaptive(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderRateLimitRemaining, "36")
+		w.Header().Set(HeaderRateLimitReset, strconv.FormatInt(reset, 10))
+	}))
+	defer server.Close()
+
+	transport := NewRateLimitTransport(nil, WithAdaptiveRateLimit())
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	limiter := transport.(*rateLimitTransport).limiter("127.0.0.1")
+	if rate := limiter.Rate(); rate < 0.009 || rate > 0.011 {
+		t.Fatalf("expected rate of about 36/hour, got %v/s", rate)
+	}
+}
+
+func TestRateLimitTransport_UnlimitedHost(t *testing.T) {
+	transport := NewRateLimitTransport(http.DefaultTransport, WithHostRateLimit("api.github.com", 1, 1))
+
+	if transport.(*rateLimitTransport).limiter("example.com") != nil {
+		t.Fatal("expected hosts without a limit not to be limited")
+	}
+	if transport.(*rateLimitTransport).limiter("API.GitHub.com") == nil {
+		t.Fatal("expected host matching to ignore case")
+	}
+}
@@ -0,0 +1,80 @@
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate limit headers understood by ParseRetryAfter.
+const (
+	HeaderRetryAfter         = "Retry-After"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+)
+
+// ParseRetryAfter returns the delay a server asks for before the next
+// request, relative to now. It reads Retry-After (delay in seconds or an
+// HTTP date) and falls back to X-RateLimit-Reset (Unix seconds, as sent by
+// GitHub, or an RFC 3339 time, as sent by Jira). Returns false if neither
+// header holds a usable value.
+func ParseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := strings.TrimSpace(header.Get(HeaderRetryAfter)); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return max(time.Duration(seconds)*time.Second, 0), true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	if reset, ok := parseRateLimitReset(header); ok {
+		return max(reset.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// parseRateLimitReset returns the time the rate limit quota resets, from
+// X-RateLimit-Reset as Unix seconds or an RFC 3339 time.
+func parseRateLimitReset(header http.Header) (time.Time, bool) {
+	value := strings.TrimSpace(header.Get(HeaderRateLimitReset))
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(epoch, 0), true
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, true
+	}
+
+	return time.Time{}, false
+}
+
+// NewHTTPErrorFromResponse creates an HTTPError for resp, using the status
+// text as message. For rate limited responses (429, or 403 with
+// X-RateLimit-Remaining: 0 as GitHub sends) and 503, the server-specified
+// delay from ParseRetryAfter is recorded in Delay. The body is not read.
+func NewHTTPErrorFromResponse(resp *http.Response) *HTTPError {
+	err := &HTTPError{Code: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	if isRateLimitResponse(resp) || resp.StatusCode == http.StatusServiceUnavailable {
+		if delay, ok := ParseRetryAfter(resp.Header, time.Now()); ok {
+			err.Delay = delay
+		}
+	}
+
+	return err
+}
+
+func isRateLimitResponse(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusForbidden &&
+		strings.TrimSpace(resp.Header.Get(HeaderRateLimitRemaining)) == "0"
+}
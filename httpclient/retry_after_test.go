@@ -0,0 +1,134 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	providererrors "github.com/valksor/go-toolkit/errors"
+	"github.com/valksor/go-toolkit/retry"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{"seconds", http.Header{"Retry-After": {"120"}}, 2 * time.Minute, true},
+		{"http date", http.Header{"Retry-After": {now.Add(30 * time.Second).Format(http.TimeFormat)}}, 30 * time.Second, true},
+		{"past date", http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, 0, true},
+		{"unix reset", http.Header{"X-Ratelimit-Reset": {fmt.Sprint(now.Add(time.Minute).Unix())}}, time.Minute, true},
+		{"rfc3339 reset", http.Header{"X-Ratelimit-Reset": {now.Add(90 * time.Second).Format(time.RFC3339)}}, 90 * time.Second, true},
+		{"retry-after wins", http.Header{"Retry-After": {"5"}, "X-Ratelimit-Reset": {fmt.Sprint(now.Add(time.Hour).Unix())}}, 5 * time.Second, true},
+		{"invalid", http.Header{"Retry-After": {"soon"}}, 0, false},
+		{"none", http.Header{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseRetryAfter(tt.header, now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ParseRetryAfter() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNewHTTPErrorFromResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		header    http.Header
+		wantDelay time.Duration
+	}{
+		{"429 with Retry-After", http.StatusTooManyRequests, http.Header{"Retry-After": {"7"}}, 7 * time.Second},
+		{"github 403 rate limit", http.StatusForbidden, http.Header{
+			"X-Ratelimit-Remaining": {"0"},
+			"Retry-After":           {"60"},
+		}, time.Minute},
+		{"plain 403", http.StatusForbidden, http.Header{"Retry-After": {"60"}}, 0},
+		{"503 with Retry-After", http.StatusServiceUnavailable, http.Header{"Retry-After": {"3"}}, 3 * time.Second},
+		{"500 ignores header", http.StatusInternalServerError, http.Header{"Retry-After": {"3"}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewHTTPErrorFromResponse(&http.Response{StatusCode: tt.code, Header: tt.header})
+			if err.Code != tt.code || err.RetryAfter() != tt.wantDelay {
+				t.Errorf("got code %d delay %v, want %d %v", err.Code, err.RetryAfter(), tt.code, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestShouldRetry_RetryAfter(t *testing.T) {
+	// A 403 is not retryable by status, but a server-specified delay makes it so
+	err := fmt.Errorf("github: %w", &HTTPError{Code: http.StatusForbidden, Delay: time.Second})
+	if !ShouldRetry(err) {
+		t.Fatal("expected error with server delay to be retryable")
+	}
+	if ShouldRetry(&HTTPError{Code: http.StatusForbidden}) {
+		t.Fatal("expected plain 403 not to be retryable")
+	}
+
+	err = providererrors.WrapHTTPError(err, "github", nil)
+
+	if delay, ok := retry.RetryAfter(err); !ok || delay != time.Second {
+		t.Fatalf("expected delay to survive wrapping, got %v", delay)
+	}
+}
+
+func TestWithRetry_HonorsRetryAfter(t *testing.T) {
+	config := RetryConfig{
+		MaxRetries:     1,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+	}
+
+	attempts := 0
+	start := time.Now()
+	err := WithRetry(context.Background(), config, func() error {
+		attempts++
+		if attempts == 1 {
+			return &HTTPError{Code: http.StatusTooManyRequests, Delay: 10 * time.Millisecond}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected server delay instead of backoff, took %v", elapsed)
+	}
+}
+
+func TestWithRetry_CapsRetryAfter(t *testing.T) {
+	config := RetryConfig{
+		MaxRetries:     1,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := WithRetry(ctx, config, func() error {
+		return &HTTPError{Code: http.StatusTooManyRequests, Delay: time.Hour}
+	})
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected last HTTP error after capped wait, got %v", err)
+	}
+}
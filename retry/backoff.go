@@ -0,0 +1,142 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Strategy selects how CalculateDelay grows the delay between attempts.
+type Strategy int
+
+const (
+	// StrategyExponential multiplies BaseDelay by ExponentialBase for every
+	// attempt. Jitter adds ±25% random variation.
+	StrategyExponential Strategy = iota
+	// StrategyFullJitter picks a random delay between zero and the
+	// exponential delay.
+	StrategyFullJitter
+	// StrategyEqualJitter keeps half of the exponential delay and picks the
+	// other half at random.
+	StrategyEqualJitter
+	// StrategyDecorrelatedJitter picks a random delay between BaseDelay and
+	// three times the previous delay.
+	StrategyDecorrelatedJitter
+	// StrategyConstant always waits BaseDelay. Jitter adds ±25% random
+	// variation.
+	StrategyConstant
+	// StrategyLinear waits BaseDelay times the attempt number. Jitter adds
+	// ±25% random variation.
+	StrategyLinear
+	// StrategyFibonacci waits BaseDelay times the Fibonacci number of the
+	// attempt (1, 1, 2, 3, 5, ...). Jitter adds ±25% random variation.
+	StrategyFibonacci
+)
+
+// String returns the strategy name.
+func (s Strategy) String() string {
+	switch s {
+	case StrategyExponential:
+		return "exponential"
+	case StrategyFullJitter:
+		return "full_jitter"
+	case StrategyEqualJitter:
+		return "equal_jitter"
+	case StrategyDecorrelatedJitter:
+		return "decorrelated_jitter"
+	case StrategyConstant:
+		return "constant"
+	case StrategyLinear:
+		return "linear"
+	case StrategyFibonacci:
+		return "fibonacci"
+	default:
+		return "unknown"
+	}
+}
+
+// RandFunc returns a pseudo-random number in [0.0, 1.0).
+type RandFunc func() float64
+
+// SeededRand returns a RandFunc producing a reproducible sequence for seed.
+// It is safe for concurrent use.
+func SeededRand(seed int64) RandFunc {
+	var mu sync.Mutex
+	//nolint:gosec // G404 - Math/rand is sufficient for jitter (non-cryptographic)
+	r := rand.New(rand.NewSource(seed))
+
+	return func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return r.Float64()
+	}
+}
+
+// random returns the next random number from Rand, or from math/rand.
+func (c Config) random() float64 {
+	if c.Rand != nil {
+		return c.Rand()
+	}
+
+	//nolint:gosec // G404 - Math/rand is sufficient for jitter (non-cryptographic)
+	return rand.Float64()
+}
+
+// exponentialDelay returns BaseDelay * ExponentialBase^attempt, capped at
+// MaxDelay.
+func (c Config) exponentialDelay(attempt int) time.Duration {
+	floatDelay := float64(c.BaseDelay) * math.Pow(c.ExponentialBase, float64(attempt))
+
+	return capDelay(floatDelay, c.MaxDelay)
+}
+
+// decorrelatedDelay returns the decorrelated jitter delay for attempt.
+// The delay depends on the previous one, so the chain is drawn from the
+// first retry on; with a seeded Rand the result is reproducible.
+func (c Config) decorrelatedDelay(attempt int) time.Duration {
+	delay := c.BaseDelay
+	for range attempt + 1 {
+		upper := float64(delay) * 3
+		floatDelay := float64(c.BaseDelay) + c.random()*(upper-float64(c.BaseDelay))
+		delay = capDelay(floatDelay, c.MaxDelay)
+	}
+
+	return delay
+}
+
+// fibonacciDelay returns BaseDelay times the Fibonacci number of attempt,
+// capped at MaxDelay.
+func (c Config) fibonacciDelay(attempt int) time.Duration {
+	prev, cur := 0.0, 1.0
+	for range attempt {
+		prev, cur = cur, prev+cur
+		// Stop once the cap is reached to avoid overflow
+		if cur*float64(c.BaseDelay) >= float64(c.MaxDelay) {
+			break
+		}
+	}
+
+	return capDelay(cur*float64(c.BaseDelay), c.MaxDelay)
+}
+
+// jitter adds ±25% random variation to delay if Jitter is set.
+func (c Config) jitter(delay time.Duration) time.Duration {
+	if !c.Jitter {
+		return delay
+	}
+
+	jitterFactor := 1.0 + (c.random()*0.5 - 0.25) // [-0.25, +0.25]
+
+	return time.Duration(float64(delay) * jitterFactor)
+}
+
+// capDelay converts floatDelay to a duration no larger than maxDelay.
+func capDelay(floatDelay float64, maxDelay time.Duration) time.Duration {
+	if floatDelay >= float64(maxDelay) {
+		return maxDelay
+	}
+
+	return time.Duration(floatDelay)
+}
@@ -0,0 +1,117 @@
+package retry
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCalculateDelay_Strategies(t *testing.T) {
+	tests := []struct {
+		strategy Strategy
+		want     []time.Duration
+	}{
+		{StrategyExponential, []time.Duration{1, 2, 4, 8, 10}},
+		{StrategyConstant, []time.Duration{1, 1, 1, 1, 1}},
+		{StrategyLinear, []time.Duration{1, 2, 3, 4, 5}},
+		{StrategyFibonacci, []time.Duration{1, 1, 2, 3, 5, 8, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.String(), func(t *testing.T) {
+			config := Config{
+				BaseDelay:       time.Second,
+				MaxDelay:        10 * time.Second,
+				ExponentialBase: 2,
+				Strategy:        tt.strategy,
+			}
+
+			for attempt, want := range tt.want {
+				if got := config.CalculateDelay(attempt); got != want*time.Second {
+					t.Errorf("attempt %d: expected %v, got %v", attempt, want*time.Second, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateDelay_JitterStrategies(t *testing.T) {
+	tests := []struct {
+		strategy Strategy
+		min, max func(attempt int) time.Duration
+	}{
+		{
+			strategy: StrategyFullJitter,
+			min:      func(int) time.Duration { return 0 },
+			max:      func(attempt int) time.Duration { return min(time.Second<<attempt, 10*time.Second) },
+		},
+		{
+			strategy: StrategyEqualJitter,
+			min:      func(attempt int) time.Duration { return min(time.Second<<attempt, 10*time.Second) / 2 },
+			max:      func(attempt int) time.Duration { return min(time.Second<<attempt, 10*time.Second) },
+		},
+		{
+			strategy: StrategyDecorrelatedJitter,
+			min:      func(int) time.Duration { return time.Second },
+			max:      func(int) time.Duration { return 10 * time.Second },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.String(), func(t *testing.T) {
+			config := Config{
+				BaseDelay:       time.Second,
+				MaxDelay:        10 * time.Second,
+				ExponentialBase: 2,
+				Strategy:        tt.strategy,
+				Rand:            SeededRand(1),
+			}
+
+			for attempt := range 6 {
+				for range 100 {
+					got := config.CalculateDelay(attempt)
+					if got < tt.min(attempt) || got > tt.max(attempt) {
+						t.Fatalf("attempt %d: delay %v outside [%v, %v]", attempt, got, tt.min(attempt), tt.max(attempt))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateDelay_Reproducible(t *testing.T) {
+	delays := func() []time.Duration {
+		config := DefaultConfig()
+		config.Rand = SeededRand(42)
+
+		var out []time.Duration
+		for _, strategy := range []Strategy{StrategyExponential, StrategyFullJitter, StrategyDecorrelatedJitter} {
+			config.Strategy = strategy
+			for attempt := range 4 {
+				out = append(out, config.CalculateDelay(attempt))
+			}
+		}
+
+		return out
+	}
+
+	first, second := delays(), delays()
+	if !slices.Equal(first, second) {
+		t.Fatalf("expected identical delays with the same seed:\n%v\n%v", first, second)
+	}
+}
+
+func TestCalculateDelay_LargeAttempt(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyExponential, StrategyLinear, StrategyFibonacci} {
+		config := Config{
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			ExponentialBase: 2,
+			Strategy:        strategy,
+		}
+
+		if got := config.CalculateDelay(1000); got != time.Minute {
+			t.Errorf("%v: expected delay capped at 1m, got %v", strategy, got)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default circuit breaker configuration values.
+const (
+	DefaultFailureThreshold = 0.5
+	DefaultMinRequests      = 10
+	DefaultWindow           = 60 * time.Second
+	DefaultCooldown         = 30 * time.Second
+	DefaultHalfOpenProbes   = 1
+)
+
+// windowBuckets is the number of buckets the failure-rate window is split into.
+const windowBuckets = 10
+
+// ErrCircuitOpen is matched by errors returned while a breaker rejects calls.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// StateClosed lets all calls through while tracking their failure rate.
+	StateClosed State = iota
+	// StateOpen rejects all calls until the cooldown has passed.
+	StateOpen
+	// StateHalfOpen lets a limited number of probe calls through to test
+	// whether the dependency has recovered.
+	StateHalfOpen
+)
+
+// String returns the state name.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitOpenError is returned when a breaker rejects a call.
+// It matches ErrCircuitOpen with errors.Is.
+type CircuitOpenError struct {
+	// Name is the breaker name.
+	Name string
+	// RetryAfter is the time left until the breaker lets a probe through,
+	// or 0 if it is half-open and all probes are in flight.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("circuit breaker %q is open", e.Name)
+	}
+
+	return ErrCircuitOpen.Error()
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerConfig holds circuit breaker configuration parameters.
+type BreakerConfig struct {
+	Name             string                            // Name reported in errors and state changes
+	FailureThreshold float64                           // Failure rate in the window that opens the breaker
+	MinRequests      int                               // Calls needed in the window before the rate is evaluated
+	Window           time.Duration                     // Rolling window for the failure rate
+	Cooldown         time.Duration                     // Time spent open before probing
+	HalfOpenProbes   int                               // Successful probes needed to close again
+	IsFailure        func(error) bool                  // Classifies failures; defaults to DefaultClassifier (optional)
+	OnStateChange    func(name string, from, to State) // Called after every state change (optional)
+}
+
+// DefaultBreakerConfig returns a breaker config with sensible defaults.
+func DefaultBreakerConfig(name string) BreakerConfig {
+	return BreakerConfig{
+		Name:             name,
+		FailureThreshold: DefaultFailureThreshold,
+		MinRequests:      DefaultMinRequests,
+		Window:           DefaultWindow,
+		Cooldown:         DefaultCooldown,
+		HalfOpenProbes:   DefaultHalfOpenProbes,
+	}
+}
+
+// windowBucket counts outcomes during one slice of the window.
+type windowBucket struct {
+	epoch    int64
+	success  int
+	failures int
+}
+
+// Breaker is a circuit breaker. It stops calls to a dependency that keeps
+// failing, so separate operations do not each retry against it, and lets
+// probe calls through after a cooldown to detect recovery.
+//
+// A Breaker is safe for concurrent use and is typically shared by all calls
+// to one dependency.
+type Breaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	buckets  [windowBuckets]windowBucket
+	openedAt time.Time
+	// generation changes with every state change, so outcomes of calls
+	// admitted in an earlier state are ignored.
+	generation uint64
+	// inFlight and probeSuccesses track half-open probes.
+	inFlight       int
+	probeSuccesses int
+}
+
+// NewBreaker creates a circuit breaker. Zero config fields take their defaults.
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	defaults := DefaultBreakerConfig(cfg.Name)
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaults.FailureThreshold
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = defaults.MinRequests
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaults.Cooldown
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = defaults.HalfOpenProbes
+	}
+
+	return &Breaker{cfg: cfg, now: time.Now}
+}
+
+// Name returns the breaker name.
+func (b *Breaker) Name() string {
+	return b.cfg.Name
+}
+
+// State returns the current state. An open breaker whose cooldown has passed
+// reports StateHalfOpen.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	change := b.advanceLocked()
+	state := b.state
+	b.mu.Unlock()
+
+	b.notify(change)
+
+	return state
+}
+
+// Execute runs fn if the breaker allows it and records the outcome.
+// When the breaker rejects the call, fn is not run and a *CircuitOpenError
+// is returned. If fn panics, the call is recorded as failed and the panic
+// is propagated.
+func (b *Breaker) Execute(ctx context.Context, fn func(context.Context) error) error {
+	generation, err := b.admit()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// Record the failure so a panicking half-open probe is not left in flight
+		if r := recover(); r != nil {
+			b.record(generation, callFailed)
+			panic(r)
+		}
+	}()
+
+	err = fn(ctx)
+	b.record(generation, b.classify(err))
+
+	return err
+}
+
+// Allow reports whether a call may proceed. If it returns a nil error, the
+// caller must report the call's outcome by calling done exactly once; the
+// error passed to done is classified with IsFailure, which by default counts
+// only errors DefaultClassifier considers transient. A nil error counts as a
+// success; context.Canceled and errors IsFailure rejects count as neither.
+// Otherwise it returns a *CircuitOpenError.
+func (b *Breaker) Allow() (done func(error), err error) {
+	generation, err := b.admit()
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+
+	return func(err error) {
+		once.Do(func() { b.record(generation, b.classify(err)) })
+	}, nil
+}
+
+// admit admits a call, returning the generation its outcome must be
+// recorded for, or a *CircuitOpenError.
+func (b *Breaker) admit() (uint64, error) {
+	b.mu.Lock()
+	change := b.advanceLocked()
+
+	switch b.state {
+	case StateOpen:
+		retryAfter := b.cfg.Cooldown - b.now().Sub(b.openedAt)
+		b.mu.Unlock()
+		b.notify(change)
+
+		return 0, &CircuitOpenError{Name: b.cfg.Name, RetryAfter: retryAfter}
+	case StateHalfOpen:
+		if b.inFlight >= b.cfg.HalfOpenProbes {
+			b.mu.Unlock()
+			b.notify(change)
+
+			return 0, &CircuitOpenError{Name: b.cfg.Name}
+		}
+		b.inFlight++
+	case StateClosed:
+	}
+
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(change)
+
+	return generation, nil
+}
+
+// Reset closes the breaker and forgets recorded outcomes.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	change := b.setStateLocked(StateClosed)
+	b.mu.Unlock()
+
+	b.notify(change)
+}
+
+// callOutcome is how a call counts towards the breaker's state.
+type callOutcome int
+
+const (
+	callSucceeded callOutcome = iota
+	callFailed
+	// callIgnored counts neither way, e.g. a call cancelled by its caller.
+	callIgnored
+)
+
+// classify returns how a call that ended with err counts. Only errors
+// IsFailure accepts are failures, and only calls without an error are
+// successes: cancellation and other errors, such as a 404, a validation
+// error or one wrapped with Permanent, say nothing about the dependency's
+// health either way.
+func (b *Breaker) classify(err error) callOutcome {
+	switch {
+	case err == nil:
+		return callSucceeded
+	case errors.Is(err, context.Canceled):
+		return callIgnored
+	case b.isFailure(err):
+		return callFailed
+	default:
+		return callIgnored
+	}
+}
+
+func (b *Breaker) isFailure(err error) bool {
+	if b.cfg.IsFailure != nil {
+		return b.cfg.IsFailure(err)
+	}
+
+	return DefaultClassifier(err)
+}
+
+// record stores the outcome of a call admitted in generation.
+func (b *Breaker) record(generation uint64, outcome callOutcome) {
+	b.mu.Lock()
+	var change *stateChange
+	if generation == b.generation {
+		change = b.recordLocked(outcome)
+	}
+	b.mu.Unlock()
+
+	b.notify(change)
+}
+
+func (b *Breaker) recordLocked(outcome callOutcome) *stateChange {
+	switch b.state {
+	case StateHalfOpen:
+		// An ignored probe frees its slot for another one
+		b.inFlight--
+		switch outcome {
+		case callFailed:
+			return b.setStateLocked(StateOpen)
+		case callIgnored:
+			return nil
+		case callSucceeded:
+		}
+		b.probeSuccesses++
+		if b.probeSuccesses >= b.cfg.HalfOpenProbes {
+			return b.setStateLocked(StateClosed)
+		}
+	case StateClosed:
+		bucket := b.bucketLocked()
+		switch outcome {
+		case callFailed:
+			bucket.failures++
+		case callSucceeded:
+			bucket.success++
+		case callIgnored:
+			return nil
+		}
+
+		total, failures := b.countsLocked()
+		if total >= b.cfg.MinRequests && float64(failures)/float64(total) >= b.cfg.FailureThreshold {
+			return b.setStateLocked(StateOpen)
+		}
+	case StateOpen:
+	}
+
+	return nil
+}
+
+// advanceLocked moves an open breaker to half-open once the cooldown has passed.
+func (b *Breaker) advanceLocked() *stateChange {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.Cooldown {
+		return b.setStateLocked(StateHalfOpen)
+	}
+
+	return nil
+}
+
+// stateChange is a transition to report once b.mu is released.
+type stateChange struct {
+	from, to State
+}
+
+func (b *Breaker) setStateLocked(to State) *stateChange {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.buckets = [windowBuckets]windowBucket{}
+	b.inFlight = 0
+	b.probeSuccesses = 0
+	if to == StateOpen {
+		b.openedAt = b.now()
+	}
+
+	if from == to {
+		return nil
+	}
+
+	return &stateChange{from: from, to: to}
+}
+
+func (b *Breaker) notify(change *stateChange) {
+	if change != nil && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(b.cfg.Name, change.from, change.to)
+	}
+}
+
+// bucketLocked returns the bucket for the current time, clearing it if it
+// still holds counts from an earlier pass over the window.
+func (b *Breaker) bucketLocked() *windowBucket {
+	epoch := b.epoch()
+	bucket := &b.buckets[epoch%windowBuckets]
+	if bucket.epoch != epoch {
+		*bucket = windowBucket{epoch: epoch}
+	}
+
+	return bucket
+}
+
+// countsLocked sums the outcomes recorded within the window.
+func (b *Breaker) countsLocked() (total, failures int) {
+	epoch := b.epoch()
+	for _, bucket := range b.buckets {
+		if epoch-bucket.epoch < windowBuckets {
+			total += bucket.success + bucket.failures
+			failures += bucket.failures
+		}
+	}
+
+	return total, failures
+}
+
+func (b *Breaker) epoch() int64 {
+	width := max(int64(b.cfg.Window/windowBuckets), 1)
+
+	return b.now().UnixNano() / width
+}
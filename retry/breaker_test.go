@@ -0,0 +1,311 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for breaker tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(cfg BreakerConfig) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := NewBreaker(cfg)
+	b.now = clock.Now
+
+	return b, clock
+}
+
+// errDown is a transient error, counted as a failure by default.
+var errDown = Retryable(errors.New("provider down"))
+
+func fail(context.Context) error    { return errDown }
+func succeed(context.Context) error { return nil }
+
+func TestBreaker_OpensOnFailureRate(t *testing.T) {
+	var changes []string
+	b, _ := newTestBreaker(BreakerConfig{
+		Name:             "github",
+		FailureThreshold: 0.5,
+		MinRequests:      4,
+		OnStateChange: func(name string, from, to State) {
+			changes = append(changes, name+":"+from.String()+"->"+to.String())
+		},
+	})
+	ctx := context.Background()
+
+	// Three failures are below MinRequests
+	_ = b.Execute(ctx, succeed)
+	_ = b.Execute(ctx, fail)
+	_ = b.Execute(ctx, fail)
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed below MinRequests, got %v", b.State())
+	}
+
+	_ = b.Execute(ctx, fail)
+	if b.State() != StateOpen {
+		t.Fatalf("expected open at 75%% failures, got %v", b.State())
+	}
+
+	called := false
+	err := b.Execute(ctx, func(context.Context) error {
+		called = true
+
+		return nil
+	})
+	if called {
+		t.Fatal("expected open breaker not to run fn")
+	}
+
+	var openErr *CircuitOpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &openErr) {
+		t.Fatalf("expected CircuitOpenError, got %v", err)
+	}
+	if openErr.Name != "github" || openErr.RetryAfter != DefaultCooldown {
+		t.Fatalf("unexpected open error: %+v", openErr)
+	}
+
+	if !slices.Equal(changes, []string{"github:closed->open"}) {
+		t.Fatalf("unexpected state changes: %v", changes)
+	}
+}
+
+func TestBreaker_WindowExpires(t *testing.T) {
+	b, clock := newTestBreaker(BreakerConfig{MinRequests: 2, Window: 10 * time.Second})
+	ctx := context.Background()
+
+	_ = b.Execute(ctx, fail)
+	clock.Advance(11 * time.Second)
+	_ = b.Execute(ctx, fail)
+
+	if b.State() != StateClosed {
+		t.Fatal("expected failures outside the window to be forgotten")
+	}
+
+	_ = b.Execute(ctx, fail)
+	if b.State() != StateOpen {
+		t.Fatal("expected two failures within the window to open the breaker")
+	}
+}
+
+func TestBreaker_HalfOpenProbes(t *testing.T) {
+	var changes []State
+	b, clock := newTestBreaker(BreakerConfig{
+		MinRequests:    1,
+		Cooldown:       time.Minute,
+		HalfOpenProbes: 2,
+		OnStateChange: func(_ string, _, to State) {
+			changes = append(changes, to)
+		},
+	})
+	ctx := context.Background()
+
+	_ = b.Execute(ctx, fail)
+	clock.Advance(30 * time.Second)
+
+	var openErr *CircuitOpenError
+	if err := b.Execute(ctx, succeed); !errors.As(err, &openErr) || openErr.RetryAfter != 30*time.Second {
+		t.Fatalf("expected rejection with 30s left, got %v", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected half-open after cooldown, got %v", b.State())
+	}
+
+	// Only HalfOpenProbes calls may be in flight
+	done1, err := b.Allow()
+	if err != nil {
+		t.Fatalf("expected first probe to be allowed: %v", err)
+	}
+	done2, err := b.Allow()
+	if err != nil {
+		t.Fatalf("expected second probe to be allowed: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected third probe to be rejected, got %v", err)
+	}
+
+	done1(nil)
+	done1(errDown) // reporting twice has no effect
+	if b.State() != StateHalfOpen {
+		t.Fatal("expected breaker to stay half-open until all probes succeed")
+	}
+	done2(nil)
+
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after successful probes, got %v", b.State())
+	}
+	if !slices.Equal(changes, []State{StateOpen, StateHalfOpen, StateClosed}) {
+		t.Fatalf("unexpected state changes: %v", changes)
+	}
+}
+
+func TestBreaker_FailedProbeReopens(t *testing.T) {
+	b, clock := newTestBreaker(BreakerConfig{MinRequests: 1, Cooldown: time.Second})
+	ctx := context.Background()
+
+	_ = b.Execute(ctx, fail)
+	clock.Advance(time.Second)
+
+	if err := b.Execute(ctx, fail); !errors.Is(err, errDown) {
+		t.Fatalf("expected probe error, got %v", err)
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("expected failed probe to reopen, got %v", b.State())
+	}
+
+	b.Reset()
+	if b.State() != StateClosed {
+		t.Fatal("expected Reset to close the breaker")
+	}
+}
+
+func TestBreaker_PanickingProbeFails(t *testing.T) {
+	b, clock := newTestBreaker(BreakerConfig{MinRequests: 1, Cooldown: time.Second})
+	ctx := context.Background()
+
+	_ = b.Execute(ctx, fail)
+	clock.Advance(time.Second)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "probe boom" {
+				t.Fatalf("expected the panic to propagate, got %v", r)
+			}
+		}()
+		_ = b.Execute(ctx, func(context.Context) error { panic("probe boom") })
+	}()
+
+	if b.State() != StateOpen {
+		t.Fatalf("expected panicking probe to reopen, got %v", b.State())
+	}
+
+	// The probe is not left in flight: after the cooldown a new one is let through
+	clock.Advance(time.Second)
+	if err := b.Execute(ctx, succeed); err != nil {
+		t.Fatalf("expected a new probe after the cooldown, got %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("expected successful probe to close, got %v", b.State())
+	}
+}
+
+func TestBreaker_StaleOutcomesIgnored(t *testing.T) {
+	b, _ := newTestBreaker(BreakerConfig{MinRequests: 1})
+
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+
+	_ = b.Execute(context.Background(), fail) // opens the breaker
+	b.Reset()
+
+	// The call admitted before the reset reports now
+	done(errDown)
+	if b.State() != StateClosed {
+		t.Fatal("expected outcome from an earlier generation to be ignored")
+	}
+}
+
+func TestBreaker_IgnoresCancellation(t *testing.T) {
+	b, _ := newTestBreaker(BreakerConfig{MinRequests: 1})
+
+	_ = b.Execute(context.Background(), func(context.Context) error { return context.Canceled })
+	if b.State() != StateClosed {
+		t.Fatal("expected cancellation not to count as failure")
+	}
+}
+
+func TestBreaker_CanceledProbeIgnored(t *testing.T) {
+	b, clock := newTestBreaker(BreakerConfig{MinRequests: 1, Cooldown: time.Second})
+	ctx := context.Background()
+
+	_ = b.Execute(ctx, fail)
+	clock.Advance(time.Second)
+
+	canceled := func(context.Context) error { return context.Canceled }
+	_ = b.Execute(ctx, canceled)
+	_ = b.Execute(ctx, func(context.Context) error { return Permanent(errors.New("bad request")) })
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected ignored probes to keep the breaker half-open, got %v", b.State())
+	}
+
+	// The slots were released: a new probe is let through and decides
+	if err := b.Execute(ctx, succeed); err != nil {
+		t.Fatalf("expected a new probe, got %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("expected successful probe to close, got %v", b.State())
+	}
+}
+
+func TestBreaker_IgnoresPermanentErrors(t *testing.T) {
+	b, _ := newTestBreaker(BreakerConfig{MinRequests: 1})
+	ctx := context.Background()
+
+	notFound := func(context.Context) error { return errors.New("issue not found") }
+	_ = b.Execute(ctx, notFound)
+	_ = b.Execute(ctx, func(context.Context) error { return Permanent(errDown) })
+	if b.State() != StateClosed {
+		t.Fatal("expected non-transient errors not to count as failures")
+	}
+
+	// A custom classifier decides instead
+	b, _ = newTestBreaker(BreakerConfig{MinRequests: 1, IsFailure: func(err error) bool { return err != nil }})
+	_ = b.Execute(ctx, notFound)
+	if b.State() != StateOpen {
+		t.Fatal("expected IsFailure to override the default")
+	}
+}
+
+func TestDoWithContext_Breaker(t *testing.T) {
+	b, _ := newTestBreaker(BreakerConfig{MinRequests: 2})
+
+	config := Config{
+		MaxAttempts:     5,
+		BaseDelay:       time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		ExponentialBase: 1,
+		IsRetryableFunc: func(error) bool { return true },
+		Breaker:         b,
+	}
+
+	attempts := 0
+	err := config.DoWithContext(context.Background(), func(context.Context) error {
+		attempts++
+
+		return errDown
+	})
+
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected retrying to stop at the open breaker, got %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts before the breaker opened, got %d", attempts)
+	}
+
+	// A separate operation is rejected without calling fn
+	err = config.DoWithContext(context.Background(), func(context.Context) error {
+		attempts++
+
+		return nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) || attempts != 2 {
+		t.Fatalf("expected immediate rejection, got %v after %d attempts", err, attempts)
+	}
+}
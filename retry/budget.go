@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Default retry budget values.
+const (
+	DefaultBudgetRatio         = 0.1
+	DefaultMinRetriesPerSecond = 10
+	// budgetMaxTokens caps the retries a budget can save up during healthy periods.
+	budgetMaxTokens = 100
+)
+
+// ErrBudgetExhausted is matched by errors returned when a retry was skipped
+// because the retry budget ran out.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Budget limits retries to a fraction of calls across all operations that
+// share it, so retries do not multiply the load on a dependency during an
+// outage.
+//
+// It is a token bucket: every operation deposits ratio tokens and every
+// retry withdraws one. A small number of retries per second is always
+// allowed so that low-traffic callers can still retry. A Budget is safe for
+// concurrent use.
+type Budget struct {
+	ratio        float64
+	minPerSecond int
+	now          func() time.Time
+
+	mu        sync.Mutex
+	tokens    float64
+	second    int64
+	used      int
+	exhausted uint64
+}
+
+// NewBudget creates a budget allowing retries for ratio of all operations
+// (e.g. 0.1 for 10%), plus minRetriesPerSecond retries per second regardless.
+// A non-positive ratio takes DefaultBudgetRatio and a zero
+// minRetriesPerSecond takes DefaultMinRetriesPerSecond; use a negative
+// minRetriesPerSecond to disable the floor.
+func NewBudget(ratio float64, minRetriesPerSecond int) *Budget {
+	if ratio <= 0 {
+		ratio = DefaultBudgetRatio
+	}
+	if minRetriesPerSecond == 0 {
+		minRetriesPerSecond = DefaultMinRetriesPerSecond
+	}
+
+	return &Budget{
+		ratio:        ratio,
+		minPerSecond: max(minRetriesPerSecond, 0),
+		now:          time.Now,
+	}
+}
+
+// Deposit records an operation, adding ratio tokens to the budget.
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+b.ratio, budgetMaxTokens)
+}
+
+// Withdraw reports whether a retry may proceed, consuming a token if needed.
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if second := b.now().Unix(); second != b.second {
+		b.second = second
+		b.used = 0
+	}
+	if b.used < b.minPerSecond {
+		b.used++
+
+		return true
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return true
+	}
+	b.exhausted++
+
+	return false
+}
+
+// Available returns the number of retries currently saved up, not counting
+// the per-second floor.
+func (b *Budget) Available() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens
+}
+
+// Exhausted returns how many retries were refused so far. A rising count is
+// a sign of a retry storm.
+func (b *Budget) Exhausted() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.exhausted
+}
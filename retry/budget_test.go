@@ -0,0 +1,156 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := NewBudget(0.5, -1)
+	b.now = clock.Now
+
+	if b.Withdraw() {
+		t.Fatal("expected empty budget to refuse retries")
+	}
+
+	for range 4 {
+		b.Deposit()
+	}
+	if b.Available() != 2 {
+		t.Fatalf("expected 2 tokens, got %v", b.Available())
+	}
+
+	if !b.Withdraw() || !b.Withdraw() {
+		t.Fatal("expected two retries to be allowed")
+	}
+	if b.Withdraw() {
+		t.Fatal("expected budget to be exhausted")
+	}
+	if b.Exhausted() != 2 {
+		t.Fatalf("expected 2 refused retries, got %d", b.Exhausted())
+	}
+}
+
+func TestBudget_MinRetriesPerSecond(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := NewBudget(0.1, 2)
+	b.now = clock.Now
+
+	if !b.Withdraw() || !b.Withdraw() {
+		t.Fatal("expected the per-second floor to allow two retries")
+	}
+	if b.Withdraw() {
+		t.Fatal("expected third retry in the same second to be refused")
+	}
+
+	clock.Advance(time.Second)
+	if !b.Withdraw() {
+		t.Fatal("expected floor to reset after a second")
+	}
+}
+
+func TestDoWithResult(t *testing.T) {
+	errTemp := mockTemporaryError{error: errors.New("temporary"), temporary: true}
+	errFatal := errors.New("fatal")
+
+	config := Config{
+		MaxAttempts:     3,
+		BaseDelay:       time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		ExponentialBase: 1,
+	}
+
+	tests := []struct {
+		name     string
+		errs     []error
+		outcome  Outcome
+		attempts int
+	}{
+		{"success", []error{nil}, OutcomeSuccess, 1},
+		{"success after retry", []error{errTemp, nil}, OutcomeSuccess, 2},
+		{"non-retryable", []error{errTemp, errFatal}, OutcomeNonRetryable, 2},
+		{"exhausted", []error{errTemp, errTemp, errTemp}, OutcomeAttemptsExhausted, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := 0
+			result := config.DoWithResult(context.Background(), func(context.Context) error {
+				err := tt.errs[call]
+				call++
+
+				return err
+			})
+
+			if result.Outcome != tt.outcome || result.Attempts != tt.attempts {
+				t.Fatalf("got %v after %d attempts, want %v after %d", result.Outcome, result.Attempts, tt.outcome, tt.attempts)
+			}
+			if result.TotalWait != time.Duration(tt.attempts-1)*time.Millisecond {
+				t.Fatalf("unexpected total wait %v", result.TotalWait)
+			}
+			if (result.Err == nil) != (tt.outcome == OutcomeSuccess) {
+				t.Fatalf("unexpected error %v", result.Err)
+			}
+			if result.Retried() != (tt.attempts > 1) {
+				t.Fatal("unexpected Retried()")
+			}
+		})
+	}
+}
+
+func TestDoWithResult_Canceled(t *testing.T) {
+	config := Config{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute, ExponentialBase: 1}
+	config.IsRetryableFunc = func(error) bool { return true }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	result := config.DoWithResult(ctx, func(context.Context) error { return errors.New("fail") })
+	if result.Outcome != OutcomeCanceled || !errors.Is(result.Err, context.DeadlineExceeded) {
+		t.Fatalf("expected canceled outcome, got %v: %v", result.Outcome, result.Err)
+	}
+	if result.TotalWait <= 0 || result.TotalWait >= time.Minute {
+		t.Fatalf("expected partial wait to be counted, got %v", result.TotalWait)
+	}
+}
+
+func TestDoWithContext_OnRetryAndBudget(t *testing.T) {
+	type retryCall struct {
+		attempt int
+		delay   time.Duration
+	}
+	var calls []retryCall
+
+	errFail := errors.New("fail")
+	budget := NewBudget(1, -1)
+	config := Config{
+		MaxAttempts:     5,
+		BaseDelay:       time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		ExponentialBase: 1,
+		IsRetryableFunc: func(error) bool { return true },
+		Budget:          budget,
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			if !errors.Is(err, errFail) {
+				t.Errorf("unexpected error %v", err)
+			}
+			calls = append(calls, retryCall{attempt, delay})
+		},
+	}
+
+	// The operation deposits one token, enough for a single retry.
+	result := config.DoWithResult(context.Background(), func(context.Context) error { return errFail })
+
+	if result.Outcome != OutcomeBudgetExhausted || result.Attempts != 2 {
+		t.Fatalf("expected budget to stop after 2 attempts, got %v after %d", result.Outcome, result.Attempts)
+	}
+	if !errors.Is(result.Err, ErrBudgetExhausted) || !errors.Is(result.Err, errFail) {
+		t.Fatalf("expected error to wrap ErrBudgetExhausted and the last error, got %v", result.Err)
+	}
+	if len(calls) != 1 || calls[0] != (retryCall{1, time.Millisecond}) {
+		t.Fatalf("unexpected OnRetry calls: %+v", calls)
+	}
+}
@@ -0,0 +1,155 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	providererrors "github.com/valksor/go-toolkit/errors"
+)
+
+// classified marks an error as permanent or retryable regardless of how it
+// would otherwise be classified.
+type classified struct {
+	err       error
+	retryable bool
+}
+
+func (e *classified) Error() string {
+	return e.err.Error()
+}
+
+func (e *classified) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so that it is never retried. The wrapped error is
+// still matched by errors.Is and errors.As. Returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &classified{err: err, retryable: false}
+}
+
+// Retryable wraps err so that it is always retried. The wrapped error is
+// still matched by errors.Is and errors.As. Returns nil if err is nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &classified{err: err, retryable: true}
+}
+
+// marked reports whether err was wrapped with Permanent or Retryable, and
+// which. The outermost wrapper wins.
+func marked(err error) (retryable, ok bool) {
+	var c *classified
+	if !errors.As(err, &c) {
+		return false, false
+	}
+
+	return c.retryable, true
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	retryable, ok := marked(err)
+
+	return ok && !retryable
+}
+
+// RetryableStatus reports whether an HTTP status code indicates a
+// transient failure: 408, 429, 502, 503 and 504.
+func RetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultClassifier is the IsRetryableFunc used when Config has none.
+// In order, it treats as retryable:
+//   - errors wrapped with Retryable (and never errors wrapped with Permanent)
+//   - context.DeadlineExceeded, such as an AttemptTimeout running out, but
+//     not context.Canceled
+//   - errors carrying a server-specified delay (see RetryAfterer)
+//   - errors wrapping ErrRateLimited or ErrNetworkError from the errors
+//     package; errors with any other error code are not retried
+//   - net.Error timeouts and *net.OpError connection failures
+//   - errors reporting an HTTP status (StatusCode() or HTTPStatusCode())
+//     accepted by RetryableStatus
+//   - errors implementing Temporary() bool that return true
+func DefaultClassifier(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if retryable, ok := marked(err); ok {
+		return retryable
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if _, ok := RetryAfter(err); ok {
+		return true
+	}
+
+	switch providererrors.GetErrorCode(err) {
+	case providererrors.ErrorCodeRateLimited, providererrors.ErrorCodeNetworkError:
+		return true
+	case providererrors.ErrorCodeUnknown:
+	default:
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	if code, ok := statusCode(err); ok {
+		return RetryableStatus(code)
+	}
+
+	var temp interface{ Temporary() bool }
+	if errors.As(err, &temp) {
+		return temp.Temporary()
+	}
+
+	return false
+}
+
+// statusCode returns the HTTP status code reported by err or any error it
+// wraps, using the same interfaces as errors.WrapHTTPError.
+func statusCode(err error) (int, bool) {
+	var sc interface{ StatusCode() int }
+	if errors.As(err, &sc) {
+		return sc.StatusCode(), true
+	}
+
+	var hs interface{ HTTPStatusCode() int }
+	if errors.As(err, &hs) {
+		return hs.HTTPStatusCode(), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,103 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	providererrors "github.com/valksor/go-toolkit/errors"
+)
+
+type statusError struct{ code int }
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", e.code) }
+func (e statusError) StatusCode() int { return e.code }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return false }
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", errors.New("boom"), false},
+		{"canceled", context.Canceled, false},
+		{"wrapped canceled", fmt.Errorf("call: %w", context.Canceled), false},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), true},
+		{"retry after", rateLimitedError{delay: time.Second}, true},
+		{"rate limited", providererrors.RateLimitedError("github", "quota"), true},
+		{"network", fmt.Errorf("%w: reset", providererrors.ErrNetworkError), true},
+		{"unauthorized", providererrors.UnauthorizedError("github", errors.New("bad token")), false},
+		{"not found", providererrors.NotFoundError("github", "issue"), false},
+		{"net timeout", fmt.Errorf("read: %w", timeoutError{}), true},
+		{"net op", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"status 503", statusError{http.StatusServiceUnavailable}, true},
+		{"status 408", fmt.Errorf("wrapped: %w", statusError{http.StatusRequestTimeout}), true},
+		{"status 500", statusError{http.StatusInternalServerError}, false},
+		{"status 400", statusError{http.StatusBadRequest}, false},
+		{"temporary", fmt.Errorf("wrapped: %w", mockTemporaryError{error: errors.New("t"), temporary: true}), true},
+		{"not temporary", mockTemporaryError{error: errors.New("t"), temporary: false}, false},
+		{"permanent", Permanent(statusError{http.StatusServiceUnavailable}), false},
+		{"retryable", Retryable(errors.New("boom")), true},
+		{"outermost wins", Permanent(fmt.Errorf("wrapped: %w", Retryable(errors.New("boom")))), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultClassifier(tt.err); got != tt.want {
+				t.Errorf("DefaultClassifier(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermanentAndRetryable(t *testing.T) {
+	if Permanent(nil) != nil || Retryable(nil) != nil {
+		t.Fatal("expected nil for nil error")
+	}
+
+	base := errors.New("boom")
+	err := Permanent(base)
+	if !errors.Is(err, base) || err.Error() != "boom" {
+		t.Fatalf("expected wrapper to be transparent, got %v", err)
+	}
+	if !IsPermanent(err) || IsPermanent(Retryable(base)) || IsPermanent(base) {
+		t.Fatal("unexpected IsPermanent result")
+	}
+
+	// Markers take precedence over a custom IsRetryableFunc
+	config := Config{IsRetryableFunc: func(error) bool { return true }}
+	if config.IsRetryable(err) {
+		t.Fatal("expected permanent error not to be retried")
+	}
+
+	config.IsRetryableFunc = func(error) bool { return false }
+	if !config.IsRetryable(Retryable(base)) {
+		t.Fatal("expected retryable error to be retried")
+	}
+}
+
+func TestDoWithContext_Permanent(t *testing.T) {
+	config := Config{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	calls := 0
+	result := config.DoWithResult(context.Background(), func(context.Context) error {
+		calls++
+
+		return Permanent(statusError{http.StatusServiceUnavailable})
+	})
+
+	if calls != 1 || result.Outcome != OutcomeNonRetryable {
+		t.Fatalf("expected a single non-retryable attempt, got %d calls and %v", calls, result.Outcome)
+	}
+}
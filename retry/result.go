@@ -0,0 +1,58 @@
+package retry
+
+import "time"
+
+// Outcome classifies how a retried operation ended.
+type Outcome int
+
+const (
+	// OutcomeSuccess means an attempt succeeded.
+	OutcomeSuccess Outcome = iota
+	// OutcomeNonRetryable means an attempt failed with a non-retryable error.
+	OutcomeNonRetryable
+	// OutcomeAttemptsExhausted means every attempt failed.
+	OutcomeAttemptsExhausted
+	// OutcomeBudgetExhausted means a retry was refused by the Budget.
+	OutcomeBudgetExhausted
+	// OutcomeCircuitOpen means the Breaker rejected an attempt.
+	OutcomeCircuitOpen
+	// OutcomeCanceled means the context was done while waiting to retry.
+	OutcomeCanceled
+)
+
+// String returns the outcome name.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeNonRetryable:
+		return "non_retryable"
+	case OutcomeAttemptsExhausted:
+		return "attempts_exhausted"
+	case OutcomeBudgetExhausted:
+		return "budget_exhausted"
+	case OutcomeCircuitOpen:
+		return "circuit_open"
+	case OutcomeCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// Result describes a retried operation.
+type Result struct {
+	// Err is the error DoWithContext returns, or nil on success.
+	Err error
+	// Attempts is the number of attempts made.
+	Attempts int
+	// TotalWait is the time spent waiting between attempts.
+	TotalWait time.Duration
+	// Outcome classifies how the operation ended.
+	Outcome Outcome
+}
+
+// Retried reports whether the operation needed more than one attempt.
+func (r Result) Retried() bool {
+	return r.Attempts > 1
+}
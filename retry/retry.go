@@ -3,9 +3,8 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
-	"math/rand"
 	"time"
 )
 
@@ -20,6 +19,10 @@ const (
 // IsRetryableFunc determines if an error should trigger a retry.
 type IsRetryableFunc func(error) bool
 
+// OnRetryFunc is called before waiting to retry. Attempt is the number of
+// the upcoming retry, starting at 1; err is the error that caused it.
+type OnRetryFunc func(attempt int, err error, delay time.Duration)
+
 // Config holds retry configuration parameters.
 type Config struct {
 	MaxAttempts     int             // Maximum number of retry attempts
@@ -27,7 +30,13 @@ type Config struct {
 	MaxDelay        time.Duration   // Maximum delay between retries
 	ExponentialBase float64         // Multiplier for exponential backoff
 	Jitter          bool            // Add randomness to delay to prevent thundering herd
-	IsRetryableFunc IsRetryableFunc // Custom function to check if error is retryable (optional)
+	IsRetryableFunc IsRetryableFunc // Custom function to check if error is retryable (default: DefaultClassifier)
+	Breaker         *Breaker        // Circuit breaker consulted before every attempt (optional)
+	Budget          *Budget         // Retry budget shared across operations (optional)
+	OnRetry         OnRetryFunc     // Called before waiting to retry (optional)
+	Strategy        Strategy        // Backoff strategy (default: StrategyExponential)
+	Rand            RandFunc        // Random source for jitter, e.g. SeededRand for tests (optional)
+	AttemptTimeout  time.Duration   // Timeout for each attempt; zero means no limit (optional)
 }
 
 // DefaultConfig returns a config with sensible defaults.
@@ -42,53 +51,47 @@ func DefaultConfig() Config {
 }
 
 // IsRetryable returns true if the error should trigger a retry.
-// If a custom IsRetryableFunc is set, it will be used.
-// Otherwise, network-related errors (implementing Temporary() bool) are retryable.
+// Errors wrapped with Permanent or Retryable are classified accordingly.
+// Otherwise IsRetryableFunc is used if set, and DefaultClassifier if not.
 func (c Config) IsRetryable(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	if retryable, ok := marked(err); ok {
+		return retryable
+	}
+
 	// Use custom function if provided
 	if c.IsRetryableFunc != nil {
 		return c.IsRetryableFunc(err)
 	}
 
-	// Check for common retryable error types
-	// In Go, we typically check for temporary errors or specific error types
-	type temporary interface {
-		Temporary() bool
-	}
-
-	if temp, ok := err.(temporary); ok && temp.Temporary() {
-		return true
-	}
-
-	// Could be extended with specific error type checks
-	// For now, default to not retrying unknown errors
-	return false
+	return DefaultClassifier(err)
 }
 
-// CalculateDelay computes the delay for a given attempt number.
-// Attempt is 0-indexed (0 = first retry).
+// CalculateDelay computes the delay for a given attempt number using
+// Strategy. Attempt is 0-indexed (0 = first retry).
 func (c Config) CalculateDelay(attempt int) time.Duration {
-	// Exponential backoff: base_delay * (exponential_base ^ attempt)
-	floatDelay := float64(c.BaseDelay) * math.Pow(c.ExponentialBase, float64(attempt))
-	delay := time.Duration(floatDelay)
-
-	// Cap at max delay
-	if delay > c.MaxDelay {
-		delay = c.MaxDelay
-	}
-
-	// Add jitter: ±25% random variation
-	if c.Jitter {
-		//nolint:gosec // G404 - Math/rand is sufficient for jitter (non-cryptographic)
-		jitterFactor := 1.0 + (rand.Float64()*0.5 - 0.25) // [-0.25, +0.25]
-		delay = time.Duration(float64(delay) * jitterFactor)
+	switch c.Strategy {
+	case StrategyFullJitter:
+		return time.Duration(c.random() * float64(c.exponentialDelay(attempt)))
+	case StrategyEqualJitter:
+		half := c.exponentialDelay(attempt) / 2
+
+		return half + time.Duration(c.random()*float64(half))
+	case StrategyDecorrelatedJitter:
+		return c.decorrelatedDelay(attempt)
+	case StrategyConstant:
+		return c.jitter(min(c.BaseDelay, c.MaxDelay))
+	case StrategyLinear:
+		return c.jitter(capDelay(float64(c.BaseDelay)*float64(attempt+1), c.MaxDelay))
+	case StrategyFibonacci:
+		return c.jitter(c.fibonacciDelay(attempt))
+	default:
+		// Exponential backoff: base_delay * (exponential_base ^ attempt)
+		return c.jitter(c.exponentialDelay(attempt))
 	}
-
-	return delay
 }
 
 // Do executes the given function, retrying on retryable errors.
@@ -100,39 +103,102 @@ func (c Config) Do(ctx context.Context, fn func() error) error {
 }
 
 // DoWithContext is like Do but allows the function to receive the context.
+// If AttemptTimeout is set, every attempt gets a child context with that
+// timeout. The error of an attempt that runs out of time is classified like
+// any other; DefaultClassifier retries context.DeadlineExceeded.
+// Errors carrying a server-specified delay (see RetryAfterer) set the wait
+// before the next attempt, capped at MaxDelay.
+// If a Breaker is set, every attempt goes through it, and retrying stops
+// with a *CircuitOpenError as soon as the breaker rejects an attempt.
+// If a Budget is set, retrying stops with an error matching
+// ErrBudgetExhausted once the budget runs out.
 func (c Config) DoWithContext(ctx context.Context, fn func(context.Context) error) error {
-	var lastErr error
+	return c.DoWithResult(ctx, fn).Err
+}
+
+// DoWithResult is like DoWithContext but also reports how many attempts
+// were made, how long was spent waiting and how the operation ended.
+func (c Config) DoWithResult(ctx context.Context, fn func(context.Context) error) Result {
+	var (
+		result  Result
+		lastErr error
+	)
+
+	finish := func(outcome Outcome, err error) Result {
+		result.Outcome = outcome
+		result.Err = err
+
+		return result
+	}
+
+	if c.Budget != nil {
+		c.Budget.Deposit()
+	}
 
 	for attempt := range c.MaxAttempts {
 		if attempt > 0 {
-			// Wait before retry
-			delay := c.CalculateDelay(attempt - 1)
+			if c.Budget != nil && !c.Budget.Withdraw() {
+				return finish(OutcomeBudgetExhausted,
+					fmt.Errorf("%w after %d attempts: %w", ErrBudgetExhausted, attempt, lastErr))
+			}
+
+			// Wait before retry, honoring a server-specified delay
+			delay := c.DelayFor(attempt-1, lastErr)
+			if c.OnRetry != nil {
+				c.OnRetry(attempt, lastErr, delay)
+			}
+
+			start := time.Now()
 			select {
 			case <-time.After(delay):
+				result.TotalWait += delay
 			case <-ctx.Done():
-				return ctx.Err()
+				result.TotalWait += time.Since(start)
+
+				return finish(OutcomeCanceled, ctx.Err())
 			}
 		}
 
-		err := fn(ctx)
+		result.Attempts++
+		err := c.attempt(ctx, fn)
 		if err == nil {
-			return nil
+			return finish(OutcomeSuccess, nil)
+		}
+		if errors.Is(err, ErrCircuitOpen) {
+			return finish(OutcomeCircuitOpen, err)
 		}
 
 		lastErr = err
 
 		// Check if error is retryable
 		if !c.IsRetryable(err) {
-			return err
+			return finish(OutcomeNonRetryable, err)
 		}
+	}
 
-		// If this was the last attempt, we'll return the error below
-		if attempt == c.MaxAttempts-1 {
-			break
-		}
+	return finish(OutcomeAttemptsExhausted,
+		fmt.Errorf("retry failed after %d attempts: %w", c.MaxAttempts, lastErr))
+}
+
+// attempt runs fn once, through the breaker if one is set.
+func (c Config) attempt(ctx context.Context, fn func(context.Context) error) error {
+	ctx, cancel := c.attemptContext(ctx)
+	defer cancel()
+
+	if c.Breaker == nil {
+		return fn(ctx)
+	}
+
+	return c.Breaker.Execute(ctx, fn)
+}
+
+// attemptContext derives the context for one attempt.
+func (c Config) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.AttemptTimeout <= 0 {
+		return ctx, func() {}
 	}
 
-	return fmt.Errorf("retry failed after %d attempts: %w", c.MaxAttempts, lastErr)
+	return context.WithTimeout(ctx, c.AttemptTimeout)
 }
 
 // RetryContext manages retry state for manual retry control.
@@ -172,8 +238,9 @@ func (r *RetryContext) HandleError(err error) bool {
 }
 
 // Delay waits for the appropriate delay before the next retry.
+// A server-specified delay carried by the last error takes precedence.
 func (r *RetryContext) Delay(ctx context.Context) error {
-	delay := r.config.CalculateDelay(r.attempt - 1)
+	delay := r.config.DelayFor(r.attempt-1, r.lastErr)
 	select {
 	case <-time.After(delay):
 		return nil
@@ -182,6 +249,12 @@ func (r *RetryContext) Delay(ctx context.Context) error {
 	}
 }
 
+// AttemptContext returns the context for the next attempt, limited by the
+// config's AttemptTimeout if set. Call cancel once the attempt is done.
+func (r *RetryContext) AttemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return r.config.attemptContext(ctx)
+}
+
 // LastError returns the most recent error encountered.
 func (r *RetryContext) LastError() error {
 	return r.lastErr
@@ -0,0 +1,41 @@
+package retry
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryAfterer is implemented by errors that carry a server-specified delay
+// before the next attempt, such as an HTTP Retry-After header.
+type RetryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// RetryAfter returns the server-specified delay carried by err or any error
+// it wraps. Returns false if there is none or it is not positive.
+func RetryAfter(err error) (time.Duration, bool) {
+	var ra RetryAfterer
+	if !errors.As(err, &ra) {
+		return 0, false
+	}
+
+	delay := ra.RetryAfter()
+
+	return delay, delay > 0
+}
+
+// DelayFor computes the delay before retrying after err on the given attempt
+// (0-indexed, 0 = first retry). If err carries a server-specified delay
+// (see RetryAfterer), that delay is used, capped at MaxDelay and without
+// jitter. Otherwise it falls back to CalculateDelay.
+func (c Config) DelayFor(attempt int, err error) time.Duration {
+	if delay, ok := RetryAfter(err); ok {
+		if c.MaxDelay > 0 && delay > c.MaxDelay {
+			return c.MaxDelay
+		}
+
+		return delay
+	}
+
+	return c.CalculateDelay(attempt)
+}
@@ -0,0 +1,104 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// rateLimitedError carries a server-specified delay.
+type rateLimitedError struct {
+	delay time.Duration
+}
+
+func (e rateLimitedError) Error() string             { return "rate limited" }
+func (e rateLimitedError) RetryAfter() time.Duration { return e.delay }
+
+func TestRetryAfter(t *testing.T) {
+	wrapped := fmt.Errorf("github: %w", rateLimitedError{delay: 5 * time.Second})
+	if delay, ok := RetryAfter(wrapped); !ok || delay != 5*time.Second {
+		t.Fatalf("expected 5s from wrapped error, got %v (ok=%v)", delay, ok)
+	}
+
+	if _, ok := RetryAfter(rateLimitedError{}); ok {
+		t.Fatal("expected zero delay to be ignored")
+	}
+	if _, ok := RetryAfter(errors.New("plain")); ok {
+		t.Fatal("expected no delay for plain errors")
+	}
+}
+
+func TestDelayFor(t *testing.T) {
+	config := Config{
+		BaseDelay:       time.Second,
+		MaxDelay:        10 * time.Second,
+		ExponentialBase: 2,
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{"server delay", rateLimitedError{delay: 3 * time.Second}, 3 * time.Second},
+		{"capped at MaxDelay", rateLimitedError{delay: time.Hour}, 10 * time.Second},
+		{"fallback to backoff", errors.New("plain"), 4 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.DelayFor(2, tt.err); got != tt.want {
+				t.Errorf("DelayFor(2) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoWithContext_HonorsRetryAfter(t *testing.T) {
+	config := Config{
+		MaxAttempts:     2,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Minute,
+		ExponentialBase: 2,
+		IsRetryableFunc: func(error) bool { return true },
+	}
+
+	attempts := 0
+	start := time.Now()
+	err := config.DoWithContext(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return rateLimitedError{delay: 10 * time.Millisecond}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected server delay instead of one minute backoff, took %v", elapsed)
+	}
+}
+
+func TestRetryContext_DelayHonorsRetryAfter(t *testing.T) {
+	rc := NewRetryContext(Config{
+		MaxAttempts:     3,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Minute,
+		ExponentialBase: 2,
+		IsRetryableFunc: func(error) bool { return true },
+	})
+
+	rc.HandleError(rateLimitedError{delay: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := rc.Delay(ctx); err != nil {
+		t.Fatalf("expected short server delay, got %v", err)
+	}
+}
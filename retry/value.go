@@ -0,0 +1,28 @@
+package retry
+
+import "context"
+
+// DoValue runs fn with config like Config.DoWithContext and returns the
+// value from the first successful attempt. On failure it returns the zero
+// value of T and the error DoWithContext would return.
+func DoValue[T any](ctx context.Context, config Config, fn func(context.Context) (T, error)) (T, error) {
+	value, result := DoValueWithResult(ctx, config, fn)
+
+	return value, result.Err
+}
+
+// DoValueWithResult is like DoValue but also reports the Result.
+func DoValueWithResult[T any](ctx context.Context, config Config, fn func(context.Context) (T, error)) (T, Result) {
+	var value T
+	result := config.DoWithResult(ctx, func(ctx context.Context) error {
+		v, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		value = v
+
+		return nil
+	})
+
+	return value, result
+}
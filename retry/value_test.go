@@ -0,0 +1,100 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDoValue(t *testing.T) {
+	config := Config{
+		MaxAttempts:     3,
+		BaseDelay:       time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		ExponentialBase: 1,
+		IsRetryableFunc: func(error) bool { return true },
+	}
+
+	calls := 0
+	value, err := DoValue(context.Background(), config, func(context.Context) (string, error) {
+		calls++
+		if calls < 2 {
+			return "partial", errors.New("fail")
+		}
+
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "ok" || calls != 2 {
+		t.Fatalf("expected ok after 2 calls, got %q after %d", value, calls)
+	}
+}
+
+func TestDoValue_Failure(t *testing.T) {
+	config := Config{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	errFatal := errors.New("fatal")
+
+	value, result := DoValueWithResult(context.Background(), config, func(context.Context) (int, error) {
+		return 42, errFatal
+	})
+	if value != 0 {
+		t.Fatalf("expected zero value on failure, got %d", value)
+	}
+	if !errors.Is(result.Err, errFatal) || result.Outcome != OutcomeNonRetryable || result.Attempts != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestDoWithContext_AttemptTimeout(t *testing.T) {
+	config := Config{
+		MaxAttempts:     3,
+		BaseDelay:       time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		ExponentialBase: 1,
+		AttemptTimeout:  10 * time.Millisecond,
+		IsRetryableFunc: func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+	}
+
+	calls := 0
+	value, err := DoValue(context.Background(), config, func(ctx context.Context) (int, error) {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected attempt context to have a deadline")
+		}
+		if calls == 1 {
+			// Hang until the attempt times out
+			<-ctx.Done()
+
+			return 0, ctx.Err()
+		}
+
+		return calls, nil
+	})
+	if err != nil {
+		t.Fatalf("expected second attempt to succeed, got %v", err)
+	}
+	if value != 2 {
+		t.Fatalf("expected value from second attempt, got %d", value)
+	}
+}
+
+func TestRetryContext_AttemptContext(t *testing.T) {
+	rc := NewRetryContext(Config{MaxAttempts: 1, AttemptTimeout: time.Minute})
+
+	ctx, cancel := rc.AttemptContext(context.Background())
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("expected attempt context to have a deadline")
+	}
+
+	ctx, cancel = NewRetryContext(Config{MaxAttempts: 1}).AttemptContext(context.Background())
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("expected no deadline without AttemptTimeout")
+	}
+}